@@ -0,0 +1,273 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// constraint is a line of a constraints file. A must-link constraint
+// keeps all its vertices in the same cluster, and a cannot-link constraint
+// keeps the vertices of one side out of the clusters of the other side
+type constraint struct {
+	line     int
+	text     string
+	mustLink bool
+	a, b     []int
+}
+
+// constraints are the constraints of a graph, with the must-link ones
+// merged into groups of vertices that stay together
+type constraints struct {
+	all []constraint
+	// group is the index in groups of each vertex in a must-link group,
+	// or -1
+	group  []int
+	groups [][]int
+}
+
+// readConstraints reads lines with "must-link" followed by one or more
+// operands, or "cannot-link" followed by two operands, where an operand
+// is an entity or, between slashes, a regular expression matching
+// entities. Blank lines and lines starting with # are ignored
+func readConstraints(r io.Reader, g *graph) (*constraints, error) {
+	cs := &constraints{group: make([]int, len(g.names))}
+	parent := make([]int, len(g.names))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(v int) int
+	find = func(v int) int {
+		if parent[v] != v {
+			parent[v] = find(parent[v])
+		}
+		return parent[v]
+	}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		t := strings.TrimSpace(scanner.Text())
+		if t == "" || strings.HasPrefix(t, "#") {
+			continue
+		}
+		fields := strings.Fields(t)
+		c := constraint{line: line, text: t}
+		switch {
+		case fields[0] == "must-link" && len(fields) > 1:
+			c.mustLink = true
+			for _, f := range fields[1:] {
+				vertices, err := operand(f, g)
+				if err != nil {
+					return nil, fmt.Errorf("line %v: %v", line, err)
+				}
+				c.a = append(c.a, vertices...)
+			}
+			for _, v := range c.a {
+				parent[find(v)] = find(c.a[0])
+			}
+		case fields[0] == "cannot-link" && len(fields) == 3:
+			var err error
+			if c.a, err = operand(fields[1], g); err == nil {
+				c.b, err = operand(fields[2], g)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("line %v: %v", line, err)
+			}
+		default:
+			return nil, fmt.Errorf("line %v: expected must-link with operands or cannot-link with two operands", line)
+		}
+		if len(c.a) == 0 || (!c.mustLink && len(c.b) == 0) {
+			fmt.Fprintf(os.Stderr, "line %v: no entities, ignored\n", line)
+			continue
+		}
+		cs.all = append(cs.all, c)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	size := map[int]int{}
+	for v := range cs.group {
+		size[find(v)]++
+	}
+	index := map[int]int{}
+	for v := range cs.group {
+		cs.group[v] = -1
+		if root := find(v); size[root] > 1 {
+			if _, ok := index[root]; !ok {
+				index[root] = len(cs.groups)
+				cs.groups = append(cs.groups, nil)
+			}
+		}
+	}
+	for v := range cs.group {
+		if i, ok := index[find(v)]; ok {
+			cs.group[v] = i
+			cs.groups[i] = append(cs.groups[i], v)
+		}
+	}
+	for _, c := range cs.all {
+		if c.mustLink {
+			continue
+		}
+		roots := map[int]bool{}
+		for _, v := range c.a {
+			roots[find(v)] = true
+		}
+		for _, v := range c.b {
+			if roots[find(v)] {
+				return nil, fmt.Errorf("line %v: %v is on both sides, through must-link constraints", c.line, g.names[v])
+			}
+		}
+	}
+	return cs, nil
+}
+
+func operand(s string, g *graph) ([]int, error) {
+	if len(s) < 2 || s[0] != '/' || s[len(s)-1] != '/' {
+		if v, ok := g.vertices[s]; ok {
+			return []int{v}, nil
+		}
+		return nil, nil
+	}
+	re, err := regexp.Compile(s[1 : len(s)-1])
+	if err != nil {
+		return nil, err
+	}
+	var vertices []int
+	for v, name := range g.names {
+		if re.MatchString(name) {
+			vertices = append(vertices, v)
+		}
+	}
+	return vertices, nil
+}
+
+// repair changes the clustering so that it satisfies the constraints:
+// each must-link group is moved to the cluster of its first vertex, and
+// then the vertices (with their groups) of the second side of each
+// cannot-link constraint are moved out of the clusters of the first side,
+// to an empty cluster for each cluster they leave. A later cannot-link
+// constraint may break an earlier one, so violations must be checked
+func (cs *constraints) repair(c []int) {
+	for _, group := range cs.groups {
+		for _, v := range group[1:] {
+			c[v] = c[group[0]]
+		}
+	}
+	var used []bool
+	free := 0
+	for _, cl := range cs.all {
+		if cl.mustLink {
+			continue
+		}
+		side := map[int]bool{}
+		for _, v := range cl.a {
+			side[c[v]] = true
+		}
+		moved := map[int]int{}
+		for _, v := range cl.b {
+			k := c[v]
+			if !side[k] {
+				continue
+			}
+			if used == nil {
+				used = make([]bool, len(c))
+				for _, k := range c {
+					used[k] = true
+				}
+			}
+			to, ok := moved[k]
+			if !ok {
+				for free < len(used) && used[free] {
+					free++
+				}
+				if free == len(used) {
+					return
+				}
+				to = free
+				used[to] = true
+				moved[k] = to
+			}
+			if i := cs.group[v]; i != -1 {
+				for _, u := range cs.groups[i] {
+					c[u] = to
+				}
+			} else {
+				c[v] = to
+			}
+		}
+	}
+}
+
+// violations returns the constraints the clustering does not satisfy,
+// with the clusters a must-link constraint spans or the clusters shared
+// by both sides of a cannot-link constraint
+func (cs *constraints) violations(c []int) []string {
+	var result []string
+	for _, cl := range cs.all {
+		clusters := map[int]bool{}
+		for _, v := range cl.a {
+			clusters[c[v]] = true
+		}
+		if cl.mustLink {
+			if len(clusters) > 1 {
+				result = append(result, fmt.Sprintf("line %v: %v spans %v clusters", cl.line, cl.text, len(clusters)))
+			}
+			continue
+		}
+		shared := map[int]bool{}
+		for _, v := range cl.b {
+			if clusters[c[v]] {
+				shared[c[v]] = true
+			}
+		}
+		if len(shared) > 0 {
+			result = append(result, fmt.Sprintf("line %v: %v shares %v clusters", cl.line, cl.text, len(shared)))
+		}
+	}
+	return result
+}
+
+// count returns the number of clusters spanned by must-link constraints
+// beyond the first plus the number of clusters shared by both sides of
+// cannot-link constraints
+func (cs *constraints) count(c []int) int {
+	n := 0
+	for _, cl := range cs.all {
+		clusters := map[int]bool{}
+		for _, v := range cl.a {
+			clusters[c[v]] = true
+		}
+		if cl.mustLink {
+			n += len(clusters) - 1
+			continue
+		}
+		shared := map[int]bool{}
+		for _, v := range cl.b {
+			if clusters[c[v]] && !shared[c[v]] {
+				shared[c[v]] = true
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// apply sets the violations of each clustering, after repairing it if
+// repair is set, and recomputes its objectives, which may include
+// penalties or refer to the clustering before repair, along with the
+// layers' if they are objectives
+func (cs *constraints) apply(g *graph, clusterings []clustering, repair, layerObjectives bool) {
+	ev := newEvaluator(g)
+	for i := range clusterings {
+		c := &clusterings[i]
+		if repair {
+			cs.repair(c.clusters)
+		}
+		c.objective = ev.objectivesWithLayers(c.clusters, layerObjectives)
+		c.constrained = true
+		c.violations = cs.violations(c.clusters)
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConstraints(t *testing.T) {
+	g, err := readGraph(strings.NewReader(triangles))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs, err := readConstraints(strings.NewReader(`# comment
+must-link a d
+must-link /^[ef]$/
+cannot-link /^[ad]$/ /^[bcef]$/
+cannot-link x a
+`), g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cs.all) != 3 || len(cs.groups) != 2 {
+		t.Fatalf("expected 3 constraints and 2 groups, got %v and %v", len(cs.all), cs.groups)
+	}
+	c := []int{0, 0, 0, 0, 0, 0}
+	if n := cs.count(c); n != 1 {
+		t.Errorf("expected 1 violation, got %v", n)
+	}
+	cs.repair(c)
+	if v := cs.violations(c); len(v) != 0 {
+		t.Errorf("expected no violations after repair, got %v (%v)", v, c)
+	}
+	if c[0] != c[3] || c[4] != c[5] || c[0] == c[1] || c[0] == c[4] {
+		t.Errorf("unexpected repair %v", c)
+	}
+	c = []int{0, 0, 1, 2, 1, 3}
+	if v := cs.violations(c); len(v) != 3 || cs.count(c) != 3 {
+		t.Errorf("expected 3 violated constraints counting 3, got %v", v)
+	}
+	_, err = readConstraints(strings.NewReader("must-link a b\ncannot-link a b\n"), g)
+	if err == nil {
+		t.Error("expected an error for contradicting constraints")
+	}
+	_, err = readConstraints(strings.NewReader("cannot-link a\n"), g)
+	if err == nil {
+		t.Error("expected an error for a missing operand")
+	}
+}
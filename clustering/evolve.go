@@ -0,0 +1,317 @@
+package main
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sync"
+
+	"github.com/project-draco/moea"
+)
+
+// evolution is moea's simple genetic algorithm, breeding each generation
+// serially, so that runs are reproducible, and evaluating it in parallel,
+// with an objective function, and so buffers, per worker
+type evolution struct {
+	config    *moea.Config
+	evaluate  []moea.ObjectiveFunc
+	selection moea.SelectionOperator
+	mutation  moea.FastMutation
+	// parents are the population from which children are bred. NSGA-II
+	// replaces them with the best of them and the previous parents, so
+	// the previous parents are kept in children until they are bred
+	parents, children                 moea.Population
+	parentObjectives, childObjectives [][]float64
+	generation                        int
+	// best is the best first objective so far, and stagnant the number of
+	// generations since it last improved
+	best     float64
+	stagnant int
+}
+
+type generationListener interface {
+	OnGeneration(*moea.Config, moea.Population, [][]float64)
+}
+
+func newEvolution(config *moea.Config, selection moea.SelectionOperator, evaluate []moea.ObjectiveFunc) *evolution {
+	e := &evolution{
+		config:           config,
+		evaluate:         evaluate,
+		selection:        selection,
+		parents:          config.Population,
+		children:         config.Population.Clone(),
+		parentObjectives: make([][]float64, config.Population.Len()),
+		childObjectives:  make([][]float64, config.Population.Len()),
+		best:             math.MaxFloat64,
+	}
+	if i, ok := selection.(interface{ Initialize(*moea.Config) }); ok {
+		i.Initialize(config)
+	}
+	e.mutation.Initialize(config)
+	return e
+}
+
+// initialize evaluates the initial population
+func (e *evolution) initialize() {
+	e.evaluateAll(e.parents, e.parentObjectives)
+	e.best = e.bestObjective()
+}
+
+// evaluateAll evaluates the population in parallel
+func (e *evolution) evaluateAll(p moea.Population, objectives [][]float64) {
+	parallelWorkers(len(e.evaluate), p.Len(), func(worker, i int) {
+		objectives[i] = e.evaluate[worker](p.Individual(i))
+	})
+}
+
+func (e *evolution) bestObjective() float64 {
+	best := math.MaxFloat64
+	for _, o := range e.parentObjectives {
+		best = math.Min(best, o[0])
+	}
+	return best
+}
+
+// step breeds and evaluates a generation
+func (e *evolution) step() {
+	if l, ok := e.selection.(generationListener); ok {
+		l.OnGeneration(e.config, e.parents, e.parentObjectives)
+	}
+	for i := 0; i < e.children.Len(); i += 2 {
+		parent1 := e.parents.Individual(e.selection.Selection(e.config, e.parentObjectives))
+		parent2 := e.parents.Individual(e.selection.Selection(e.config, e.parentObjectives))
+		child1, child2 := e.children.Individual(i), e.children.Individual(i+1)
+		e.crossover(parent1, parent2, child1, child2)
+		e.mutation.Mutation(e.config, child1, e.config.MutationProbability)
+		e.mutation.Mutation(e.config, child2, e.config.MutationProbability)
+	}
+	e.evaluateAll(e.children, e.childObjectives)
+	e.parents, e.children = e.children, e.parents
+	e.parentObjectives, e.childObjectives = e.childObjectives, e.parentObjectives
+	e.generation++
+	if best := e.bestObjective(); best < e.best-epsilon {
+		e.best = best
+		e.stagnant = 0
+	} else {
+		e.stagnant++
+	}
+}
+
+// crossover is moea's one-point crossover
+func (e *evolution) crossover(parent1, parent2, child1, child2 moea.Individual) {
+	rng := e.config.RandomNumberGenerator
+	if !rng.Flip(e.config.CrossoverProbability) {
+		child1.Copy(parent1, 0, child1.Len())
+		child2.Copy(parent2, 0, child2.Len())
+		return
+	}
+	cross := 1 + int(rng.Float64()*float64(parent1.Len()-2))
+	child1.Copy(parent1, 0, cross)
+	child1.Copy(parent2, cross, child1.Len())
+	child2.Copy(parent2, 0, cross)
+	child2.Copy(parent1, cross, child2.Len())
+}
+
+// finish returns the final population, which NSGA-II selects from the
+// last parents and children, and the index of its best individual
+func (e *evolution) finish() *moea.Result {
+	r := &moea.Result{
+		BestObjective: make([]float64, e.config.NumberOfObjectives),
+		Individuals:   make([]moea.IndividualResult, e.parents.Len()),
+	}
+	for k := range r.BestObjective {
+		r.BestObjective[k] = math.MaxFloat64
+	}
+	for i := range r.Individuals {
+		r.Individuals[i].Objective = e.parentObjectives[i]
+		r.Individuals[i].Values = make([]interface{}, e.config.NumberOfValues)
+		for j := range r.Individuals[i].Values {
+			r.Individuals[i].Values[j] = e.parents.Individual(i).Value(j)
+		}
+	}
+	type finalizer interface {
+		Finalize(*moea.Config, moea.Population, [][]float64, *moea.Result)
+	}
+	// NSGA-II merges with the previous parents, which exist after the
+	// first generation
+	if f, ok := e.selection.(finalizer); ok && e.generation > 0 {
+		f.Finalize(e.config, e.parents, e.parentObjectives, r)
+	}
+	r.BestIndividualIndex = 0
+	for i, ind := range r.Individuals {
+		if ind.Objective[0] < r.Individuals[r.BestIndividualIndex].Objective[0] {
+			r.BestIndividualIndex = i
+		}
+	}
+	r.BestObjective[0] = r.Individuals[r.BestIndividualIndex].Objective[0]
+	return r
+}
+
+// state returns the state of the evolution, along with the state of its
+// random number generator, to be checkpointed
+func (e *evolution) state(seed uint32, rng *xorshift) *repetitionState {
+	s := &repetitionState{
+		Seed:       seed,
+		Generation: e.generation,
+		RNG:        [4]uint32{rng.x, rng.y, rng.z, rng.w},
+		Best:       e.best,
+		Stagnant:   e.stagnant,
+		Parents:    populationClusters(e.parents, e.config.NumberOfValues),
+	}
+	if _, ok := e.selection.(generationListener); ok {
+		s.Previous = populationClusters(e.children, e.config.NumberOfValues)
+	}
+	return s
+}
+
+// restore continues the evolution from a checkpointed state, which must
+// be of the same number of individuals and vertices
+func (e *evolution) restore(s *repetitionState, rng *xorshift, lbits int) error {
+	if len(s.Parents) != e.parents.Len() {
+		return fmt.Errorf("checkpoint has %v individuals, expected %v", len(s.Parents), e.parents.Len())
+	}
+	for i, c := range s.Parents {
+		if len(c) != e.config.NumberOfValues {
+			return fmt.Errorf("checkpoint has %v vertices, expected %v", len(c), e.config.NumberOfValues)
+		}
+		setClusters(e.parents.Individual(i), c, lbits)
+	}
+	e.evaluateAll(e.parents, e.parentObjectives)
+	if l, ok := e.selection.(generationListener); ok && s.Previous != nil {
+		if len(s.Previous) != e.children.Len() {
+			return fmt.Errorf("checkpoint has %v previous individuals, expected %v", len(s.Previous), e.children.Len())
+		}
+		for i, c := range s.Previous {
+			setClusters(e.children.Individual(i), c, lbits)
+		}
+		e.evaluateAll(e.children, e.childObjectives)
+		// NSGA-II remembers the previous parents to select from them
+		// along with the children in the next generation
+		l.OnGeneration(e.config, e.children, e.childObjectives)
+	}
+	e.generation, e.best, e.stagnant = s.Generation, s.Best, s.Stagnant
+	rng.x, rng.y, rng.z, rng.w = s.RNG[0], s.RNG[1], s.RNG[2], s.RNG[3]
+	return nil
+}
+
+func populationClusters(p moea.Population, n int) [][]int {
+	result := make([][]int, p.Len())
+	for i := range result {
+		result[i] = clustersOf(p.Individual(i), n)
+	}
+	return result
+}
+
+// checkpoint is the state of the repetitions of the genetic algorithm,
+// saved to a gzipped JSON file so that a long run can be resumed
+type checkpoint struct {
+	MDGHash    string `json:"mdgHash"`
+	Parameters string `json:"parameters"`
+	Seed       int64  `json:"seed"`
+	// Repetitions has the state of each repetition, or nil for those
+	// not checkpointed yet
+	Repetitions []*repetitionState `json:"repetitions"`
+}
+
+// repetitionState is the state of a repetition after a generation, with
+// the clusters of each individual of the population
+type repetitionState struct {
+	Seed       uint32    `json:"seed"`
+	Generation int       `json:"generation"`
+	RNG        [4]uint32 `json:"rng"`
+	Best       float64   `json:"best"`
+	Stagnant   int       `json:"stagnant"`
+	Parents    [][]int   `json:"parents"`
+	// Previous are the previous parents, for NSGA-II
+	Previous [][]int `json:"previous,omitempty"`
+}
+
+func readCheckpoint(name string) (*checkpoint, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	var ck checkpoint
+	if err := json.NewDecoder(r).Decode(&ck); err != nil {
+		return nil, fmt.Errorf("%v: %v", name, err)
+	}
+	return &ck, nil
+}
+
+// check returns an error if the checkpoint is not of a run with the given
+// MDG, parameters, seed and number of repetitions
+func (ck *checkpoint) check(hash, parameters string, seed int64, repetitions int) error {
+	switch {
+	case ck.MDGHash != hash:
+		return fmt.Errorf("checkpoint is of another MDG, with sha256 %v", ck.MDGHash)
+	case ck.Parameters != parameters:
+		return fmt.Errorf("checkpoint has other parameters: %v", ck.Parameters)
+	case ck.Seed != seed:
+		return fmt.Errorf("checkpoint has another seed: %v", ck.Seed)
+	case len(ck.Repetitions) != repetitions:
+		return fmt.Errorf("checkpoint has %v repetitions, expected %v", len(ck.Repetitions), repetitions)
+	}
+	return nil
+}
+
+// checkpointer saves the states of the repetitions, which run in
+// parallel, to the same file, replacing it only once it is written
+type checkpointer struct {
+	mu   sync.Mutex
+	file string
+	ck   checkpoint
+}
+
+func (cp *checkpointer) save(repetition int, s *repetitionState) error {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	cp.ck.Repetitions[repetition] = s
+	tmp := cp.file + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	w := gzip.NewWriter(f)
+	err = json.NewEncoder(w).Encode(&cp.ck)
+	if err == nil {
+		err = w.Close()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, cp.file)
+}
+
+// xorshift is moea's Xorshift random number generator, giving the same
+// numbers for the same seed, with a state that can be checkpointed
+type xorshift struct{ x, y, z, w uint32 }
+
+func newXorshift(seed uint32) *xorshift {
+	return &xorshift{123456789, 362436069, 521288629, seed}
+}
+
+func (s *xorshift) next() uint32 {
+	t := s.x ^ (s.x << 11)
+	s.x, s.y, s.z = s.y, s.z, s.w
+	s.w = s.w ^ (s.w >> 19) ^ (t ^ (t >> 8))
+	return s.w
+}
+
+func (s *xorshift) Flip(probability float64) bool {
+	return s.next() < uint32(probability*moea.MaxUint32AsFloat)
+}
+
+func (s *xorshift) FairFlip() bool { return s.next() < moea.HalfMaxUint32 }
+
+func (s *xorshift) Float64() float64 { return float64(s.next()) / moea.MaxUint32AsFloat }
@@ -0,0 +1,340 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/project-draco/moea"
+	"github.com/project-draco/moea/binary"
+	"github.com/project-draco/moea/nsgaii"
+)
+
+// gaOptions are the options of runGA
+type gaOptions struct {
+	// mono selects a mono-objective algorithm with tournament selection
+	// instead of NSGA-II
+	mono bool
+	// integer selects integer encoding, where each value is a cluster,
+	// instead of binary encoding, where each value is a binary string
+	integer bool
+	// constraints, if any, are enforced by repairing each individual
+	// before evaluating it or, if penalty is set, by adding the number of
+	// violations to the negative Turbo MQ, as a cluster contributes at most
+	// 1 to Turbo MQ
+	constraints *constraints
+	penalty     bool
+	// warmStart, if set, seeds a fraction of the initial population and,
+	// if stability is positive, the vertices moved from it cost stability
+	// in Turbo MQ, if all of them moved
+	warmStart    *warmStart
+	warmFraction float64
+	stability    float64
+	// layerObjectives adds the negative Turbo MQ of each layer of the
+	// graph to the objectives, so that NSGA-II finds clusterings balancing
+	// them
+	layerObjectives bool
+	// workers is the number of goroutines evaluating the population of
+	// each repetition, by default the processors not taken by other
+	// repetitions
+	workers int
+	// maxTime, if positive, stops all repetitions once it elapses, and
+	// stagnation, if positive, stops a repetition once its best Turbo MQ
+	// has not improved for that many generations
+	maxTime    time.Duration
+	stagnation int
+	// checkpoint, if set, is the file where the state of the repetitions
+	// is saved every checkpointInterval and when they stop, and resume, if
+	// set, is the checkpoint from which they continue
+	checkpoint         string
+	checkpointInterval time.Duration
+	resume             *checkpoint
+}
+
+// progressInterval is how often each repetition reports its progress
+const progressInterval = 10 * time.Second
+
+// runGA clusters the graph with a genetic algorithm, returning the final
+// population of the best repetition and its best individual
+func runGA(g *graph, o gaOptions, seed int64, repeat int) ([]clustering, int, error) {
+	if o.warmStart != nil {
+		if err := o.warmStart.check(g); err != nil {
+			return nil, 0, err
+		}
+	}
+	n := len(g.names)
+	// each individual has len(vertices) values representing the cluster each vertex belongs
+	lengths := make([]int, n)
+	lbits := int(math.Ceil(math.Log2(float64(n)/2) - 0.5))
+	for i := 0; i < n; i++ {
+		lengths[i] = lbits
+	}
+	// set crossover probability according to Brian S. Mitchel (2002, p 93)
+	var cp float64
+	if n <= 100 {
+		cp = 0.8
+	} else if n >= 1000 {
+		cp = 1.0
+	} else {
+		cp = 0.8 + 0.2*(float64(n)-100)/899
+	}
+	// set population size and max generations adapted from to Ivan Candela et al. (2016, p 93)
+	// except for the limit of 300, that is 150 in the referenced work, and the limit of 3000
+	// that is new
+	var ps, mg int
+	if n > 10000 {
+		ps = n / 4
+		mg = n
+	} else if n > 3000 {
+		ps = n / 2
+		mg = 5 * n
+	} else if n > 300 {
+		ps = 1 * n
+		mg = 20 * n
+	} else {
+		ps = 2 * n
+		mg = 50 * n
+	}
+	// This is the typical mutatation rate for binary encoding according to Brian S. Mitchel (2002, p 93)
+	mp := 16.0 / (math.Sqrt(float64(n)) * 1000)
+	encoding := "binary"
+	if o.integer {
+		encoding = "integer"
+		// a value changes with the probability of any of its bits flipping
+		mp = 1 - math.Pow(1-mp, float64(lbits))
+	}
+	objectives := 5
+	if o.layerObjectives {
+		objectives += len(g.layers)
+	}
+	// each worker has its own evaluator and buffer
+	newObjectiveFunc := func() moea.ObjectiveFunc {
+		ev := newEvaluator(g)
+		c := make([]int, n)
+		return func(individual moea.Individual) []float64 {
+			clusters := c
+			if ind, ok := individual.(*integerIndividual); ok {
+				clusters = ind.clusters
+			} else {
+				for i := 0; i < n; i++ {
+					c[i] = int(individual.Value(i).(binary.BinaryString).Int().Int64())
+				}
+			}
+			if o.constraints != nil && !o.penalty {
+				copy(c, clusters)
+				o.constraints.repair(c)
+				clusters = c
+			}
+			objective := ev.objectivesWithLayers(clusters, o.layerObjectives)
+			if o.constraints != nil && o.penalty {
+				objective[0] += float64(o.constraints.count(clusters))
+			}
+			if o.warmStart != nil && o.stability > 0 {
+				objective[0] += o.warmStart.stability(clusters, o.stability)
+			}
+			return objective
+		}
+	}
+	seeds := repetitionSeeds(seed, repeat)
+	workers := o.workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0) / len(seeds)
+		if workers < 1 {
+			workers = 1
+		}
+	}
+	m := metadata{
+		algorithm: "ga",
+		seed:      seed,
+		parameters: fmt.Sprintf("mono=%v encoding=%v population=%v generations=%v crossover=%v mutation=%v",
+			o.mono, encoding, ps, mg, cp, mp),
+		mdgHash: g.hash,
+	}
+	if o.warmStart != nil {
+		m.parameters += fmt.Sprintf(" warm-start=%v warm-fraction=%v stability=%v", o.warmStart.name, o.warmFraction, o.stability)
+	}
+	if o.layerObjectives {
+		m.parameters += " layer-objectives=true"
+	}
+	var cpr *checkpointer
+	if o.resume != nil {
+		if err := o.resume.check(g.hash, m.parameters, seed, len(seeds)); err != nil {
+			return nil, 0, err
+		}
+	}
+	if o.checkpoint != "" {
+		cpr = &checkpointer{file: o.checkpoint, ck: checkpoint{
+			MDGHash:     g.hash,
+			Parameters:  m.parameters,
+			Seed:        seed,
+			Repetitions: make([]*repetitionState, len(seeds)),
+		}}
+		if o.resume != nil {
+			copy(cpr.ck.Repetitions, o.resume.Repetitions)
+		}
+	}
+	// the time budget and stagnation change when runs stop, so they are
+	// recorded, but a checkpoint may be resumed with others
+	if o.maxTime > 0 {
+		m.parameters += fmt.Sprintf(" max-time=%v", o.maxTime)
+	}
+	if o.stagnation > 0 {
+		m.parameters += fmt.Sprintf(" stagnation=%v", o.stagnation)
+	}
+	var start time.Time
+	run := func(repetition int, seed uint32) (*moea.Result, error) {
+		rng := newXorshift(seed)
+		var population moea.Population
+		if o.integer {
+			population = newRandomIntegerPopulation(ps, n, n/2, rng)
+		} else {
+			population = binary.NewRandomBinaryPopulation(ps, lengths, nil /*bounds*/, rng)
+		}
+		if o.warmStart != nil {
+			o.warmStart.seedPopulation(population, int(o.warmFraction*float64(population.Len())), n, lbits, rng)
+		}
+		var selection moea.SelectionOperator
+		if o.mono {
+			selection = &moea.TournamentSelection{TournamentSize: 10}
+		} else {
+			selection = &nsgaii.NsgaIISelection{}
+		}
+		evaluate := make([]moea.ObjectiveFunc, workers)
+		for i := range evaluate {
+			evaluate[i] = newObjectiveFunc()
+		}
+		e := newEvolution(&moea.Config{
+			Population:            population,
+			NumberOfObjectives:    objectives,
+			NumberOfValues:        n,
+			MaxGenerations:        mg,
+			CrossoverProbability:  cp,
+			MutationProbability:   mp,
+			RandomNumberGenerator: rng,
+		}, selection, evaluate)
+		if s := resumed(o.resume, repetition); s != nil {
+			if err := e.restore(s, rng, lbits); err != nil {
+				return nil, err
+			}
+			fmt.Fprintf(os.Stderr, "repetition %v: resumed at generation %v\n", repetition, e.generation)
+		} else {
+			e.initialize()
+		}
+		progress, saved := time.Now(), time.Now()
+		for e.generation < mg {
+			e.step()
+			stop := ""
+			if o.maxTime > 0 && time.Since(start) >= o.maxTime {
+				stop = "time budget exhausted"
+			} else if o.stagnation > 0 && e.stagnant >= o.stagnation {
+				stop = fmt.Sprintf("no improvement in %v generations", e.stagnant)
+			}
+			if time.Since(progress) >= progressInterval {
+				progress = time.Now()
+				fmt.Fprintf(os.Stderr, "repetition %v: generation %v of %v, best mq %v, %v\n",
+					repetition, e.generation, mg, -e.best, time.Since(start).Round(time.Second))
+			}
+			if cpr != nil && stop == "" && time.Since(saved) >= o.checkpointInterval {
+				saved = time.Now()
+				if err := cpr.save(repetition, e.state(seed, rng)); err != nil {
+					return nil, err
+				}
+			}
+			if stop != "" {
+				fmt.Fprintf(os.Stderr, "repetition %v: stopped at generation %v of %v: %v\n", repetition, e.generation, mg, stop)
+				break
+			}
+		}
+		if cpr != nil {
+			if err := cpr.save(repetition, e.state(seed, rng)); err != nil {
+				return nil, err
+			}
+		}
+		return e.finish(), nil
+	}
+	fmt.Fprintf(os.Stderr, "Max Generations: %v, Population Size: %v, Individual Size: %v, Variables: %v, Workers: %v\n",
+		mg, ps, lbits*ps, len(lengths), workers)
+	start = time.Now()
+	result, best, err := runRepeatedly(run, seeds)
+	if err != nil {
+		return nil, 0, err
+	}
+	m.repetition = best
+	m.repetitionSeed = seeds[best]
+	var clusterings []clustering
+	for _, ind := range result.Individuals {
+		clusterings = append(clusterings, clustering{clusters: individualClusters(ind), objective: ind.Objective, meta: m})
+	}
+	return clusterings, result.BestIndividualIndex, nil
+}
+
+// resumed returns the checkpointed state of a repetition, if any
+func resumed(ck *checkpoint, repetition int) *repetitionState {
+	if ck == nil {
+		return nil
+	}
+	return ck.Repetitions[repetition]
+}
+
+// clustersOf returns the cluster of each of the n vertices of an
+// individual
+func clustersOf(ind moea.Individual, n int) []int {
+	if ind, ok := ind.(*integerIndividual); ok {
+		return append([]int{}, ind.clusters...)
+	}
+	c := make([]int, n)
+	for v := range c {
+		c[v] = int(ind.Value(v).(binary.BinaryString).Int().Int64())
+	}
+	return c
+}
+
+// setClusters sets the cluster of each vertex of an individual. Binary
+// individuals have lbits per vertex, the most significant first, and are
+// changed by flipping bits
+func setClusters(ind moea.Individual, c []int, lbits int) {
+	if ind, ok := ind.(*integerIndividual); ok {
+		copy(ind.clusters, c)
+		return
+	}
+	current := clustersOf(ind, len(c))
+	var flips []int
+	for v := range c {
+		for j := 0; j < lbits; j++ {
+			bit := uint(lbits - 1 - j)
+			if (current[v]>>bit)&1 != (c[v]>>bit)&1 {
+				flips = append(flips, flipIndex(v*lbits+j, ind.Len()))
+			}
+		}
+	}
+	ind.Mutate(flips)
+}
+
+// flipIndex returns the index that flips bit i of a binary individual of
+// the given length. moea's binary strings flip, in their last word of l
+// bits, the bit i mod l instead of the bit i, which is harmless to random
+// mutations but not to setting values
+func flipIndex(i, length int) int {
+	w := i / bits.UintSize
+	l := length % bits.UintSize
+	if l == 0 || w < (length-1)/bits.UintSize {
+		return i
+	}
+	start := w * bits.UintSize
+	return start + ((i-2*start)%l+l)%l
+}
+
+func individualClusters(ind moea.IndividualResult) []int {
+	c := make([]int, len(ind.Values))
+	for i := range c {
+		if v, ok := ind.Values[i].(int); ok {
+			c[i] = v
+		} else {
+			c[i] = int(ind.Values[i].(binary.BinaryString).Int().Int64())
+		}
+	}
+	return c
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/project-draco/moea"
+	"github.com/project-draco/moea/binary"
+)
+
+func TestIntegerIndividual(t *testing.T) {
+	p := newRandomIntegerPopulation(3, 50, 4, moea.NewXorshiftWithSeed(1))
+	if p.Len() != 4 {
+		t.Fatalf("expected an even population of 4, got %v", p.Len())
+	}
+	a, b := p[0].(*integerIndividual), p[1].(*integerIndividual)
+	for _, c := range a.clusters {
+		if c < 0 || c > 4 {
+			t.Fatalf("cluster %v out of bounds", c)
+		}
+	}
+	want := append(append([]int{}, a.clusters[:10]...), b.clusters[10:]...)
+	a.Copy(b, 10, 50)
+	if !reflect.DeepEqual(a.clusters, want) {
+		t.Errorf("expected %v, got %v", want, a.clusters)
+	}
+	c := p.Clone().Individual(0).(*integerIndividual)
+	c.clusters[0] = -1
+	if a.clusters[0] == -1 {
+		t.Error("clone shares values with the original")
+	}
+}
+
+func TestRunGA(t *testing.T) {
+	g := randomGraph(t, 20, 40, 3)
+	for _, o := range []gaOptions{{}, {integer: true}, {mono: true, integer: true}} {
+		c1, best1, err := runGA(g, o, 42, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c2, best2, _ := runGA(g, o, 42, 2)
+		if best1 != best2 || c1[best1].digraph(g.names) != c2[best2].digraph(g.names) {
+			t.Errorf("%+v: not reproducible", o)
+		}
+	}
+}
+
+func TestSetClusters(t *testing.T) {
+	// 29 vertices of 4 bits leave 52 bits in the last word
+	n, lbits := 29, 4
+	lengths := make([]int, n)
+	want := make([]int, n)
+	for v := range lengths {
+		lengths[v] = lbits
+		want[v] = v % 16
+	}
+	rng := moea.NewXorshiftWithSeed(1)
+	for _, p := range []moea.Population{
+		binary.NewRandomBinaryPopulation(2, lengths, nil, rng),
+		newRandomIntegerPopulation(2, n, 15, rng),
+	} {
+		setClusters(p.Individual(0), want, lbits)
+		if c := clustersOf(p.Individual(0), n); !reflect.DeepEqual(c, want) {
+			t.Errorf("%T: expected %v, got %v", p, want, c)
+		}
+	}
+}
+
+func TestCheckpoint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "clustering")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	g := randomGraph(t, 20, 40, 3)
+	for _, o := range []gaOptions{{}, {mono: true, integer: true}} {
+		c, best, err := runGA(g, o, 42, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// stop early, then resume with more workers
+		file := filepath.Join(dir, "checkpoint.gz")
+		stopped := o
+		stopped.stagnation, stopped.checkpoint = 5, file
+		if _, _, err := runGA(g, stopped, 42, 2); err != nil {
+			t.Fatal(err)
+		}
+		ck, err := readCheckpoint(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ck.Repetitions[0] == nil || ck.Repetitions[0].Generation == 0 {
+			t.Fatalf("%+v: repetition not checkpointed", o)
+		}
+		resumed := o
+		resumed.resume, resumed.workers = ck, 3
+		r, rbest, err := runGA(g, resumed, 42, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rbest != best || r[rbest].digraph(g.names) != c[best].digraph(g.names) {
+			t.Errorf("%+v: resumed run differs", o)
+		}
+		if _, _, err := runGA(g, resumed, 43, 2); err == nil {
+			t.Errorf("%+v: resumed with another seed", o)
+		}
+	}
+}
+
+func benchmarkGA(b *testing.B, o gaOptions) {
+	g := randomGraph(b, 100, 300, 4)
+	mq := 0.0
+	for i := 0; i < b.N; i++ {
+		c, best, err := runGA(g, o, int64(i+1), 1)
+		if err != nil {
+			b.Fatal(err)
+		}
+		mq += -c[best].objective[0]
+	}
+	b.ReportMetric(mq/float64(b.N), "mq")
+}
+
+func BenchmarkGABinary(b *testing.B) { benchmarkGA(b, gaOptions{}) }
+
+func BenchmarkGAInteger(b *testing.B) { benchmarkGA(b, gaOptions{integer: true}) }
+
+func BenchmarkGABinaryMono(b *testing.B) { benchmarkGA(b, gaOptions{mono: true}) }
+
+func BenchmarkGAIntegerMono(b *testing.B) { benchmarkGA(b, gaOptions{mono: true, integer: true}) }
+
+func TestLayerObjectivesAfterApply(t *testing.T) {
+	dir, err := ioutil.TempDir("", "clustering")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	static := filepath.Join(dir, "static.mdg")
+	cochange := filepath.Join(dir, "co-change.mdg")
+	ioutil.WriteFile(static, []byte("a b\nb c\nc d\nd e\ne f\n"), 0644)
+	ioutil.WriteFile(cochange, []byte("a f 3\nb e 2\nc d 1\n"), 0644)
+	g, err := readGraphs([]string{static, cochange}, []float64{1, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs, err := readConstraints(strings.NewReader("must-link a b\n"), g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ws := newWarmStart("previous.dot", []string{"x", "x", "x", "y", "y", "y"})
+	for _, o := range []gaOptions{
+		{layerObjectives: true, constraints: cs},
+		{layerObjectives: true, warmStart: ws, warmFraction: 0.5, stability: 1},
+	} {
+		clusterings, _, err := runGA(g, o, 42, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if o.constraints != nil {
+			cs.apply(g, clusterings, true, true)
+		} else {
+			ws.apply(g, clusterings, true)
+		}
+		ev := newEvaluator(g)
+		for _, c := range clusterings {
+			want := append(ev.objectives(c.clusters), ev.layers(c.clusters)...)
+			if !reflect.DeepEqual(c.objective, want) {
+				t.Fatalf("expected objectives %v, got %v", want, c.objective)
+			}
+		}
+		// the front is of all objectives, the layers' included
+		for _, i := range front(clusterings) {
+			if len(clusterings[i].objective) != 5+len(g.layers) {
+				t.Errorf("expected %v objectives in the front, got %v", 5+len(g.layers), clusterings[i].objective)
+			}
+		}
+	}
+}
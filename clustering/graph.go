@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+type edge struct{ source, destination int }
+
+type edgeWithWeight struct {
+	edge   edge
+	weight float64
+}
+
+// graph is a MDG whose vertices are numbered in order of appearance
+type graph struct {
+	names    []string
+	vertices map[string]int
+	// edges are kept in input order, so that the objective function sums
+	// the weights in the same order in every run with the same seed
+	edges []edgeWithWeight
+	// hash is the SHA-256 hash of the MDG as read, or the hashes of the
+	// MDGs, separated by commas
+	hash string
+	// layers are the MDGs, if several were blended
+	layers []layer
+}
+
+func (g *graph) indexOf(name string) int {
+	if index, ok := g.vertices[name]; ok {
+		return index
+	}
+	index := len(g.names)
+	g.vertices[name] = index
+	g.names = append(g.names, name)
+	return index
+}
+
+// readGraph reads a MDG whose lines have a source, a destination and an
+// optional integer weight, separated by tabs or spaces. A repeated edge
+// takes the weight of its last line
+func readGraph(r io.Reader) (*graph, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	g := &graph{vertices: map[string]int{}, hash: fmt.Sprintf("%x", sha256.Sum256(data))}
+	g.edges, err = g.parse(data)
+	return g, err
+}
+
+// parse returns the edges of a MDG, numbering new vertices
+func (g *graph) parse(data []byte) ([]edgeWithWeight, error) {
+	var edges []edgeWithWeight
+	index := map[edge]int{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		var arr []string
+		t := strings.TrimSpace(strings.Replace(scanner.Text(), "  ", " ", -1))
+		if len(t) == 0 {
+			continue
+		}
+		if strings.Index(t, "\t") != -1 {
+			arr = strings.Split(t, "\t")
+		} else {
+			arr = strings.Split(t, " ")
+		}
+		weigth := 1.0
+		if len(arr) > 2 {
+			w, err := strconv.Atoi(arr[2])
+			if err != nil {
+				return nil, err
+			}
+			weigth = float64(w)
+		}
+		e := edge{g.indexOf(arr[0]), g.indexOf(arr[1])}
+		if i, ok := index[e]; ok {
+			edges[i].weight = weigth
+			continue
+		}
+		index[e] = len(edges)
+		edges = append(edges, edgeWithWeight{edge: e, weight: weigth})
+	}
+	return edges, scanner.Err()
+}
+
+// layer is one of several MDGs clustered together
+type layer struct {
+	name   string
+	weight float64
+	edges  []edgeWithWeight
+}
+
+// readGraphs reads several MDGs over the same entities, such as static
+// and co-change dependencies, blending them into a graph whose edges have
+// the weighted sum of each MDG's weights, normalized by its total weight,
+// so that the weights are the relative importance of each MDG regardless
+// of their scales. The MDGs are kept as layers
+func readGraphs(names []string, weights []float64) (*graph, error) {
+	g := &graph{vertices: map[string]int{}}
+	var hashes []string
+	index := map[edge]int{}
+	for i, name := range names {
+		data, err := ioutil.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, fmt.Sprintf("%x", sha256.Sum256(data)))
+		edges, err := g.parse(data)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", name, err)
+		}
+		g.layers = append(g.layers, layer{name, weights[i], edges})
+		total := 0.0
+		for _, e := range edges {
+			total += e.weight
+		}
+		for _, e := range edges {
+			j, ok := index[e.edge]
+			if !ok {
+				j = len(g.edges)
+				index[e.edge] = j
+				g.edges = append(g.edges, edgeWithWeight{edge: e.edge})
+			}
+			g.edges[j].weight += weights[i] * e.weight / total
+		}
+	}
+	g.hash = strings.Join(hashes, ",")
+	return g, nil
+}
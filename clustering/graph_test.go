@@ -0,0 +1,40 @@
+package main
+
+import (
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadGraphs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "clustering")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	static := filepath.Join(dir, "static.mdg")
+	cochange := filepath.Join(dir, "co-change.mdg")
+	ioutil.WriteFile(static, []byte("a b\nb c\n"), 0644)
+	ioutil.WriteFile(cochange, []byte("a b 30\nc d 10\n"), 0644)
+	g, err := readGraphs([]string{static, cochange}, []float64{1, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.names) != 4 || len(g.layers) != 2 {
+		t.Fatalf("expected 4 vertices and 2 layers, got %v and %v", g.names, len(g.layers))
+	}
+	// each MDG's weights are normalized by its total weight
+	want := []float64{1*0.5 + 2*0.75, 1 * 0.5, 2 * 0.25}
+	for i, e := range g.edges {
+		if math.Abs(e.weight-want[i]) > 1e-9 {
+			t.Errorf("edge %v: expected weight %v, got %v", i, want[i], e.weight)
+		}
+	}
+	// a, b and c together, d alone
+	mq := newEvaluator(g).layers([]int{0, 0, 0, 1})
+	if mq[0] != -1 || math.Abs(mq[1]+2*30/(2*30+10.0)) > 1e-9 {
+		t.Errorf("unexpected Turbo MQ of the layers %v", mq)
+	}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+)
+
+// quotient returns the graph whose vertices are the given clusters, named
+// cluster<i> in order of appearance, along with the vertex of each
+// cluster. Edges between clusters are summed and, as in Bunch (Mitchell,
+// 2002), edges inside a cluster are dropped, so that the quotient's Turbo
+// MQ rewards merging the clusters that depend on each other
+func quotient(g *graph, clusters []int) (*graph, []int) {
+	q := &graph{vertices: map[string]int{}, hash: g.hash}
+	number := map[int]int{}
+	vertex := make([]int, len(clusters))
+	for v, k := range clusters {
+		if _, ok := number[k]; !ok {
+			number[k] = q.indexOf(fmt.Sprintf("cluster%v", len(number)))
+		}
+		vertex[v] = number[k]
+	}
+	weights := map[edge]float64{}
+	for _, e := range g.edges {
+		if u, v := vertex[e.edge.source], vertex[e.edge.destination]; u != v {
+			weights[edge{u, v}] += e.weight
+		}
+	}
+	for e, w := range weights {
+		q.edges = append(q.edges, edgeWithWeight{e, w})
+	}
+	// edges are sorted, so that the weights are summed in the same order
+	// in every run
+	sort.Slice(q.edges, func(i, j int) bool {
+		a, b := q.edges[i].edge, q.edges[j].edge
+		return a.source < b.source || a.source == b.source && a.destination < b.destination
+	})
+	return q, vertex
+}
+
+// minQuotient is the smallest quotient graph clustered again, as merging
+// two clusters only leaves the whole system
+const minQuotient = 3
+
+// gaReclusterer returns the recluster function of hierarchical with ga,
+// with the options of the first level but those referring to the vertices
+// of the MDG, as the warm start, constraints, layers and checkpoints
+func gaReclusterer(o gaOptions, seed int64, repeat int) func(q *graph) ([]int, error) {
+	o = gaOptions{mono: o.mono, integer: o.integer, workers: o.workers}
+	return func(q *graph) ([]int, error) {
+		cs, b, err := runGA(q, o, seed, repeat)
+		if err != nil {
+			return nil, err
+		}
+		return cs[b].clusters, nil
+	}
+}
+
+// hillClimbingReclusterer returns the recluster function of hierarchical
+// with hill-climbing, with the options of the first level but the warm
+// start, whose vertices are the MDG's
+func hillClimbingReclusterer(o hillClimbingOptions, seed int64, repeat int) func(q *graph) ([]int, error) {
+	o.warmStart = nil
+	return func(q *graph) ([]int, error) {
+		cs, b, err := runHillClimbing(q, o, seed, repeat)
+		if err != nil {
+			return nil, err
+		}
+		return cs[b].clusters, nil
+	}
+}
+
+// hierarchical returns the clustering with a hierarchy whose first level is
+// its clusters, and each next level the clusters of the quotient graph of
+// the previous one, as clustered by recluster, for as long as they are
+// fewer, but more than one, and improve the quotient's Turbo MQ over
+// keeping each cluster apart. The Turbo MQ and modularity of each level are of the MDG, and
+// the clusters and objectives of the result are still the first level's
+func hierarchical(g *graph, c clustering, recluster func(q *graph) ([]int, error)) (clustering, error) {
+	ev := newEvaluator(g)
+	wg := newWeightedGraph(g)
+	newLevel := func(number int, clusters []int) level {
+		return level{number, clusters, -ev.objectives(clusters)[0], wg.modularity(clusters, 1)}
+	}
+	c.hierarchy = []level{newLevel(1, c.clusters)}
+	for {
+		last := c.hierarchy[len(c.hierarchy)-1]
+		q, vertex := quotient(g, last.clusters)
+		if len(q.names) < minQuotient {
+			break
+		}
+		qc, err := recluster(q)
+		if err != nil {
+			return c, err
+		}
+		// keeping each cluster apart has no edges inside clusters, and so
+		// a Turbo MQ of 0
+		if k := count(qc); k < 2 || k >= len(q.names) || -newEvaluator(q).objectives(qc)[0] <= epsilon {
+			break
+		}
+		clusters := make([]int, len(vertex))
+		for v := range clusters {
+			clusters[v] = qc[vertex[v]]
+		}
+		c.hierarchy = append(c.hierarchy, newLevel(last.number+1, clusters))
+	}
+	c.meta.parameters += " hierarchy=true"
+	return c, nil
+}
+
+// treeNode is a cluster of the tree written by writeTree, named as in the
+// DOT, with its clusters of the previous level or, at level 1, its
+// entities. The root is the whole system, one level above the clusters
+type treeNode struct {
+	Name     string      `json:"name"`
+	Level    int         `json:"level"`
+	Children []*treeNode `json:"children,omitempty"`
+	Entities []string    `json:"entities,omitempty"`
+}
+
+// tree returns the clusters of the clustering as a tree, with the
+// clusters of each level nested as in digraph
+func (c clustering) tree(names []string) *treeNode {
+	if len(c.hierarchy) > 1 {
+		top := len(c.hierarchy) - 1
+		return &treeNode{Name: "root", Level: c.hierarchy[top].number + 1, Children: c.treeLevel(names, top, nil)}
+	}
+	number := 1
+	if len(c.hierarchy) == 1 {
+		number = c.hierarchy[0].number
+	}
+	root := &treeNode{Name: "root", Level: number + 1}
+	m := members(c.clusters, nil)
+	keys := sortedKeys(m)
+	// numbered in order of appearance, as in digraph
+	sort.Slice(keys, func(i, j int) bool { return m[keys[i]][0] < m[keys[j]][0] })
+	for i, k := range keys {
+		node := &treeNode{Name: fmt.Sprintf("cluster%v", i), Level: number}
+		for _, v := range m[k] {
+			node.Entities = append(node.Entities, names[v])
+		}
+		root.Children = append(root.Children, node)
+	}
+	return root
+}
+
+// treeLevel returns the clusters of level l having the given vertices
+// (all, if nil), as writeLevel writes them
+func (c clustering) treeLevel(names []string, l int, vertices []int) []*treeNode {
+	h := c.hierarchy[l]
+	m := members(h.clusters, vertices)
+	var nodes []*treeNode
+	for _, k := range sortedKeys(m) {
+		node := &treeNode{Name: fmt.Sprintf("cluster%v_%v", h.number, k), Level: h.number}
+		if l > 0 {
+			node.Children = c.treeLevel(names, l-1, m[k])
+		} else {
+			for _, v := range m[k] {
+				node.Entities = append(node.Entities, names[v])
+			}
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+// writeTree writes the tree of the clustering as JSON
+func writeTree(name string, names []string, c clustering) error {
+	data, err := json.MarshalIndent(c.tree(names), "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(name, append(data, '\n'), 0644)
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// four pairs, the first two and the last two joined by single edges
+const pairs = "a b\nb a\nc d\nd c\ne f\nf e\ng h\nh g\nb c\nf g\n"
+
+func TestQuotient(t *testing.T) {
+	g, err := readGraph(strings.NewReader(pairs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, vertex := quotient(g, []int{3, 3, 1, 1, 0, 0, 2, 2})
+	if want := []string{"cluster0", "cluster1", "cluster2", "cluster3"}; !reflect.DeepEqual(q.names, want) {
+		t.Errorf("expected vertices %v, got %v", want, q.names)
+	}
+	if want := []int{0, 0, 1, 1, 2, 2, 3, 3}; !reflect.DeepEqual(vertex, want) {
+		t.Errorf("expected vertex of each cluster %v, got %v", want, vertex)
+	}
+	// edges inside clusters are dropped
+	want := []edgeWithWeight{{edge{0, 1}, 1}, {edge{2, 3}, 1}}
+	if !reflect.DeepEqual(q.edges, want) {
+		t.Errorf("expected edges %v, got %v", want, q.edges)
+	}
+}
+
+func TestHierarchical(t *testing.T) {
+	g, err := readGraph(strings.NewReader(pairs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := []int{0, 0, 1, 1, 2, 2, 3, 3}
+	c := clustering{clusters: first, objective: newEvaluator(g).objectives(first)}
+	c, err = hierarchical(g, c, func(q *graph) ([]int, error) {
+		cs, best, err := runHillClimbing(q, hillClimbingOptions{steepest: true}, 1, 3)
+		if err != nil {
+			return nil, err
+		}
+		return cs[best].clusters, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the quotient of the second level has two vertices only
+	if len(c.hierarchy) != 2 {
+		t.Fatalf("expected 2 levels, got %v", len(c.hierarchy))
+	}
+	second := c.hierarchy[1].clusters
+	if second[0] != second[3] || second[4] != second[7] || second[0] == second[4] {
+		t.Errorf("expected the first and the last two pairs together, got %v", second)
+	}
+	if !reflect.DeepEqual(c.clusters, first) || math.Abs(c.hierarchy[0].mq-3.2) > 1e-9 {
+		t.Errorf("expected the first level's clusters and Turbo MQ, got %v and %v", c.clusters, c.hierarchy[0].mq)
+	}
+	root := c.tree(g.names)
+	if root.Level != 3 || len(root.Children) != 2 || len(root.Children[0].Children) != 2 {
+		t.Fatalf("unexpected tree %+v", root)
+	}
+	leaf := root.Children[0].Children[0]
+	if !strings.HasPrefix(leaf.Name, "cluster1_") || leaf.Level != 1 || len(leaf.Entities) != 2 {
+		t.Errorf("unexpected cluster %+v", leaf)
+	}
+	if d := c.digraph(g.names); !strings.Contains(d, "subgraph "+root.Children[0].Name+" {\nsubgraph "+leaf.Name+" {\n") {
+		t.Errorf("expected the tree's names in the DOT, got\n%v", d)
+	}
+}
+
+func TestHierarchicalWarmStart(t *testing.T) {
+	g, err := readGraph(strings.NewReader(pairs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ws := newWarmStart("previous.dot", []string{"x", "x", "y", "y", "z", "z", "w", "w"})
+	// the warm start is of the MDG's vertices, and not of the quotient's
+	hc := hillClimbingOptions{steepest: true, warmStart: ws}
+	cs, best, err := runHillClimbing(g, hc, 1, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := hierarchical(g, cs[best], hillClimbingReclusterer(hc, 1, 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.hierarchy) != 2 {
+		t.Errorf("expected 2 levels, got %v", len(c.hierarchy))
+	}
+	ga := gaOptions{warmStart: ws, warmFraction: 0.5, stability: 1}
+	cs, best, err = runGA(g, ga, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = hierarchical(g, cs[best], gaReclusterer(ga, 1, 1)); err != nil {
+		t.Fatal(err)
+	}
+	q, _ := quotient(g, cs[best].clusters)
+	if _, _, err := runHillClimbing(q, hc, 1, 1); err == nil {
+		t.Error("expected an error warm starting from another graph")
+	}
+}
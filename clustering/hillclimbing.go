@@ -0,0 +1,209 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// epsilon is the smallest Turbo MQ improvement considered, so that
+// rounding errors do not make the search cycle
+const epsilon = 1e-12
+
+// climber searches for the clustering with the best Turbo MQ by moving one
+// vertex at a time to another cluster, as Bunch does (Mitchell, 2002).
+// Its partition computes the change in Turbo MQ of a move from the
+// vertex's neighbors only
+type climber struct {
+	*partition
+	rng *rand.Rand
+}
+
+func newClimber(g *graph, seed uint32) *climber {
+	return &climber{newPartition(g), rand.New(rand.NewSource(int64(seed)))}
+}
+
+// randomize assigns each vertex to a random cluster among half as many
+// clusters as vertices, as the genetic algorithm does
+func (c *climber) randomize() {
+	n := len(c.cluster)
+	clusters := make([]int, n)
+	for i := range clusters {
+		clusters[i] = c.rng.Intn(n/2 + 1)
+	}
+	c.assign(clusters)
+}
+
+// emptyCluster returns a cluster without vertices, if v may leave its own
+func (c *climber) emptyCluster(v int) int {
+	if c.size[c.cluster[v]] < 2 {
+		return -1
+	}
+	for k, s := range c.size {
+		if s == 0 {
+			return k
+		}
+	}
+	return -1
+}
+
+// bestMove returns the move of v to a neighbor cluster, or to an empty
+// cluster, that most improves Turbo MQ
+func (c *climber) bestMove(v int) (b int, d float64) {
+	b = -1
+	targets := c.weightsTo(v)
+	if e := c.emptyCluster(v); e != -1 {
+		targets = append(targets, e)
+	}
+	for _, k := range targets {
+		if k == c.cluster[v] {
+			continue
+		}
+		if dk := c.delta(v, k); dk > d+epsilon {
+			b, d = k, dk
+		}
+	}
+	c.resetWeights()
+	return
+}
+
+// steepestAscent applies, at each step, the best move of all vertices,
+// until no move improves Turbo MQ
+func (c *climber) steepestAscent() {
+	for {
+		bv, bb, bd := -1, -1, 0.0
+		for v := range c.cluster {
+			if b, d := c.bestMove(v); b != -1 && d > bd {
+				bv, bb, bd = v, b, d
+			}
+		}
+		if bv == -1 {
+			return
+		}
+		c.move(bv, bb)
+	}
+}
+
+// nextAscent visits the vertices in random order, applying the best move
+// of each one that improves Turbo MQ, until no move improves it
+func (c *climber) nextAscent() {
+	for improved := true; improved; {
+		improved = false
+		for _, v := range c.rng.Perm(len(c.cluster)) {
+			if b, _ := c.bestMove(v); b != -1 {
+				c.move(v, b)
+				improved = true
+			}
+		}
+	}
+}
+
+// anneal moves random vertices to random neighbor (or empty) clusters,
+// accepting worse clusterings with probability exp(Δ/T). The temperature
+// starts at t0 and is multiplied by cooling after as many moves as
+// vertices, until it is a thousandth of t0. The best clustering found
+// is kept
+func (c *climber) anneal(t0, cooling float64) {
+	best := append([]int{}, c.cluster...)
+	bestMQ := c.mq()
+	for t := t0; t > t0/1000; t *= cooling {
+		for range c.cluster {
+			v := c.rng.Intn(len(c.cluster))
+			targets := c.weightsTo(v)
+			if e := c.emptyCluster(v); e != -1 {
+				targets = append(targets, e)
+			}
+			if len(targets) == 0 {
+				c.resetWeights()
+				continue
+			}
+			b := targets[c.rng.Intn(len(targets))]
+			if b == c.cluster[v] {
+				c.resetWeights()
+				continue
+			}
+			d := c.delta(v, b)
+			c.resetWeights()
+			if d > 0 || c.rng.Float64() < math.Exp(d/t) {
+				c.move(v, b)
+			}
+		}
+		if c.mq() > bestMQ+epsilon {
+			copy(best, c.cluster)
+			bestMQ = c.mq()
+		}
+	}
+	c.assign(best)
+}
+
+// hillClimbingOptions are the options of runHillClimbing
+type hillClimbingOptions struct {
+	steepest    bool
+	annealing   bool
+	temperature float64
+	cooling     float64
+	// warmStart, if set, is where the first repetition starts from, and
+	// the others from perturbations of it
+	warmStart *warmStart
+}
+
+// runHillClimbing clusters the graph from a random clustering for each
+// repetition, returning the local optimum of each one and the best of
+// them
+func runHillClimbing(g *graph, o hillClimbingOptions, seed int64, repeat int) ([]clustering, int, error) {
+	if o.annealing && (o.temperature <= 0 || o.cooling <= 0 || o.cooling >= 1) {
+		return nil, 0, fmt.Errorf("temperature must be positive and cooling between 0 and 1")
+	}
+	if o.warmStart != nil {
+		if err := o.warmStart.check(g); err != nil {
+			return nil, 0, err
+		}
+	}
+	seeds := repetitionSeeds(seed, repeat)
+	clusterings := make([]clustering, len(seeds))
+	parameters := fmt.Sprintf("ascent=next annealing=%v", o.annealing)
+	if o.steepest {
+		parameters = fmt.Sprintf("ascent=steepest annealing=%v", o.annealing)
+	}
+	if o.annealing {
+		parameters += fmt.Sprintf(" temperature=%v cooling=%v", o.temperature, o.cooling)
+	}
+	if o.warmStart != nil {
+		parameters += fmt.Sprintf(" warm-start=%v", o.warmStart.name)
+	}
+	parallel(len(seeds), func(i int) {
+		c := newClimber(g, seeds[i])
+		if o.warmStart != nil {
+			c.assign(o.warmStart.randomSeed(c.rng, len(g.names)/2, i == 0))
+		} else {
+			c.randomize()
+		}
+		if o.annealing {
+			c.anneal(o.temperature, o.cooling)
+		}
+		if o.steepest {
+			c.steepestAscent()
+		} else {
+			c.nextAscent()
+		}
+		clusterings[i] = clustering{
+			clusters:  c.cluster,
+			objective: c.objectives(),
+			meta: metadata{
+				algorithm:      "hill-climbing",
+				seed:           seed,
+				repetition:     i,
+				repetitionSeed: seeds[i],
+				parameters:     parameters,
+				mdgHash:        g.hash,
+			},
+		}
+	})
+	best := 0
+	for i := range clusterings {
+		if clusterings[i].objective[0] < clusterings[best].objective[0] {
+			best = i
+		}
+	}
+	return clusterings, best, nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func randomGraph(t testing.TB, n, m int, seed int64) *graph {
+	rng := rand.New(rand.NewSource(seed))
+	var buf strings.Builder
+	for i := 0; i < m; i++ {
+		fmt.Fprintf(&buf, "v%v\tv%v\t%v\n", rng.Intn(n), rng.Intn(n), rng.Intn(5)+1)
+	}
+	g, err := readGraph(strings.NewReader(buf.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func TestClimberDelta(t *testing.T) {
+	g := randomGraph(t, 40, 120, 1)
+	c := newClimber(g, 1)
+	c.randomize()
+	if mq := -referenceObjectives(g, c.cluster)[0]; math.Abs(mq-c.mq()) > 1e-9 {
+		t.Fatalf("initial mq: expected %v, got %v", mq, c.mq())
+	}
+	for i := 0; i < 1000; i++ {
+		v := c.rng.Intn(len(c.cluster))
+		b := c.rng.Intn(len(c.cluster))
+		if b == c.cluster[v] {
+			continue
+		}
+		c.weightsTo(v)
+		d := c.delta(v, b)
+		c.resetWeights()
+		before := c.mq()
+		c.move(v, b)
+		if mq := -referenceObjectives(g, c.cluster)[0]; math.Abs(mq-c.mq()) > 1e-9 || math.Abs(before+d-mq) > 1e-9 {
+			t.Fatalf("move %v: expected %v, got %v and delta %v", i, mq, c.mq(), d)
+		}
+	}
+}
+
+func TestRunHillClimbing(t *testing.T) {
+	g := randomGraph(t, 60, 150, 2)
+	for _, o := range []hillClimbingOptions{
+		{steepest: true},
+		{steepest: false},
+		{steepest: true, annealing: true, temperature: 0.1, cooling: 0.9},
+	} {
+		c1, best1, err := runHillClimbing(g, o, 42, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c2, best2, _ := runHillClimbing(g, o, 42, 3)
+		if best1 != best2 || c1[best1].digraph(g.names) != c2[best2].digraph(g.names) {
+			t.Errorf("%+v: not reproducible", o)
+		}
+		for i, c := range c1 {
+			if c.objective[0] < c1[best1].objective[0] {
+				t.Errorf("%+v: repetition %v is better than the best", o, i)
+			}
+		}
+	}
+}
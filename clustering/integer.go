@@ -0,0 +1,74 @@
+package main
+
+import "github.com/project-draco/moea"
+
+// integerPopulation is a population of individuals whose values are the
+// clusters of the vertices. It replaces moea's integer package, whose
+// Copy copies in the wrong direction and whose Clone shares individuals
+type integerPopulation []moea.Individual
+
+// integerIndividual has, for each vertex, its cluster, from 0 to max
+type integerIndividual struct {
+	clusters []int
+	max      int
+	rng      moea.RNG
+}
+
+// newRandomIntegerPopulation returns size individuals, rounded up to an
+// even number as the algorithm breeds them in pairs, with n random
+// clusters from 0 to max
+func newRandomIntegerPopulation(size, n, max int, rng moea.RNG) integerPopulation {
+	if size%2 == 1 {
+		size++
+	}
+	p := make(integerPopulation, size)
+	for i := range p {
+		ind := &integerIndividual{make([]int, n), max, rng}
+		for j := range ind.clusters {
+			ind.clusters[j] = ind.random()
+		}
+		p[i] = ind
+	}
+	return p
+}
+
+func (p integerPopulation) Len() int { return len(p) }
+
+func (p integerPopulation) Individual(i int) moea.Individual { return p[i] }
+
+func (p integerPopulation) Clone() moea.Population {
+	c := make(integerPopulation, len(p))
+	for i := range p {
+		c[i] = p[i].Clone()
+	}
+	return c
+}
+
+func (ind *integerIndividual) Len() int { return len(ind.clusters) }
+
+func (ind *integerIndividual) Value(i int) interface{} { return ind.clusters[i] }
+
+// Copy copies the values from start to end of the given individual into
+// this one, as moea's crossover expects
+func (ind *integerIndividual) Copy(from moea.Individual, start, end int) {
+	copy(ind.clusters[start:end], from.(*integerIndividual).clusters[start:end])
+}
+
+// Mutate moves the vertices at the given indexes to random clusters
+func (ind *integerIndividual) Mutate(indexes []int) {
+	for _, i := range indexes {
+		ind.clusters[i] = ind.random()
+	}
+}
+
+func (ind *integerIndividual) Clone() moea.Individual {
+	return &integerIndividual{append([]int{}, ind.clusters...), ind.max, ind.rng}
+}
+
+func (ind *integerIndividual) random() int {
+	c := int(ind.rng.Float64() * float64(ind.max+1))
+	if c > ind.max {
+		c = ind.max
+	}
+	return c
+}
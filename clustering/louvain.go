@@ -0,0 +1,273 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+type neighbor struct {
+	vertex int
+	weight float64
+}
+
+// undirected returns, for each vertex, its neighbors with the weights of
+// the edges in both directions summed, and the weight of its self-loop
+func undirected(g *graph) ([][]neighbor, []float64) {
+	n := len(g.names)
+	adjacency := make([][]neighbor, n)
+	self := make([]float64, n)
+	index := make([]map[int]int, n)
+	for _, e := range g.edges {
+		u, v := e.edge.source, e.edge.destination
+		if u == v {
+			self[u] += e.weight
+			continue
+		}
+		for _, p := range [][2]int{{u, v}, {v, u}} {
+			if index[p[0]] == nil {
+				index[p[0]] = map[int]int{}
+			}
+			i, ok := index[p[0]][p[1]]
+			if !ok {
+				i = len(adjacency[p[0]])
+				index[p[0]][p[1]] = i
+				adjacency[p[0]] = append(adjacency[p[0]], neighbor{p[1], 0})
+			}
+			adjacency[p[0]][i].weight += e.weight
+		}
+	}
+	return adjacency, self
+}
+
+// weightedGraph is an undirected graph whose vertices are either the MDG's
+// vertices or the communities of the previous level of the Louvain method
+type weightedGraph struct {
+	adjacency [][]neighbor
+	self      []float64
+	// degree counts self-loops twice, so that the degrees sum to 2m
+	degree []float64
+	m      float64
+}
+
+func newWeightedGraph(g *graph) *weightedGraph {
+	wg := &weightedGraph{degree: make([]float64, len(g.names))}
+	wg.adjacency, wg.self = undirected(g)
+	for v := range wg.adjacency {
+		wg.degree[v] = 2 * wg.self[v]
+		wg.m += wg.self[v]
+		for _, nb := range wg.adjacency[v] {
+			wg.degree[v] += nb.weight
+			wg.m += nb.weight / 2
+		}
+	}
+	return wg
+}
+
+// moveVertices moves each vertex, in random order, to the neighbor
+// community with the largest modularity gain, until no vertex moves. It
+// returns the community of each vertex, numbered in order of appearance,
+// and the number of communities
+func (wg *weightedGraph) moveVertices(rng *rand.Rand, resolution float64) ([]int, int) {
+	n := len(wg.adjacency)
+	community := make([]int, n)
+	total := make([]float64, n)
+	for v := range community {
+		community[v] = v
+		total[v] = wg.degree[v]
+	}
+	weights := make([]float64, n)
+	var touched []int
+	for moved := true; moved; {
+		moved = false
+		for _, v := range rng.Perm(n) {
+			c := community[v]
+			touched = touched[:0]
+			for _, nb := range wg.adjacency[v] {
+				k := community[nb.vertex]
+				if weights[k] == 0 {
+					touched = append(touched, k)
+				}
+				weights[k] += nb.weight
+			}
+			// the gain of adding v to community k, times m, is
+			// weights[k] - resolution*total[k]*degree[v]/2m
+			total[c] -= wg.degree[v]
+			best := c
+			bestGain := weights[c] - resolution*total[c]*wg.degree[v]/(2*wg.m)
+			for _, k := range touched {
+				gain := weights[k] - resolution*total[k]*wg.degree[v]/(2*wg.m)
+				if gain > bestGain+epsilon {
+					best, bestGain = k, gain
+				}
+			}
+			total[best] += wg.degree[v]
+			if best != c {
+				community[v] = best
+				moved = true
+			}
+			for _, k := range touched {
+				weights[k] = 0
+			}
+		}
+	}
+	number := map[int]int{}
+	for v, c := range community {
+		if _, ok := number[c]; !ok {
+			number[c] = len(number)
+		}
+		community[v] = number[c]
+	}
+	return community, len(number)
+}
+
+// aggregate returns the graph whose vertices are the given communities
+func (wg *weightedGraph) aggregate(community []int, k int) *weightedGraph {
+	a := &weightedGraph{
+		adjacency: make([][]neighbor, k),
+		self:      make([]float64, k),
+		degree:    make([]float64, k),
+		m:         wg.m,
+	}
+	members := make([][]int, k)
+	for v, c := range community {
+		members[c] = append(members[c], v)
+	}
+	weights := make([]float64, k)
+	var touched []int
+	for c := range members {
+		touched = touched[:0]
+		for _, v := range members[c] {
+			a.self[c] += wg.self[v]
+			a.degree[c] += wg.degree[v]
+			for _, nb := range wg.adjacency[v] {
+				d := community[nb.vertex]
+				if d == c {
+					// each edge is seen from both of its vertices
+					a.self[c] += nb.weight / 2
+					continue
+				}
+				if weights[d] == 0 {
+					touched = append(touched, d)
+				}
+				weights[d] += nb.weight
+			}
+		}
+		for _, d := range touched {
+			a.adjacency[c] = append(a.adjacency[c], neighbor{d, weights[d]})
+			weights[d] = 0
+		}
+	}
+	return a
+}
+
+// modularity returns the modularity of the given communities, with the
+// expected weight inside communities multiplied by the resolution
+func (wg *weightedGraph) modularity(community []int, resolution float64) float64 {
+	if wg.m == 0 {
+		return 0
+	}
+	inside := map[int]float64{}
+	total := map[int]float64{}
+	for v, c := range community {
+		inside[c] += wg.self[v]
+		total[c] += wg.degree[v]
+		for _, nb := range wg.adjacency[v] {
+			if community[nb.vertex] == c {
+				inside[c] += nb.weight / 2
+			}
+		}
+	}
+	q := 0.0
+	for c := range total {
+		q += inside[c]/wg.m - resolution*(total[c]/(2*wg.m))*(total[c]/(2*wg.m))
+	}
+	return q
+}
+
+// louvain clusters the graph with the Louvain method (Blondel et al.,
+// 2008), returning the community of each vertex at each level, from the
+// finest to the coarsest. There is a single level, of singletons, if no
+// vertices are merged
+func louvain(wg *weightedGraph, rng *rand.Rand, resolution float64) [][]int {
+	n := len(wg.adjacency)
+	assignment := make([]int, n)
+	for v := range assignment {
+		assignment[v] = v
+	}
+	var levels [][]int
+	for g := wg; g.m > 0; {
+		community, k := g.moveVertices(rng, resolution)
+		if k == len(g.adjacency) {
+			break
+		}
+		for v := range assignment {
+			assignment[v] = community[assignment[v]]
+		}
+		levels = append(levels, append([]int{}, assignment...))
+		g = g.aggregate(community, k)
+	}
+	if len(levels) == 0 {
+		levels = append(levels, assignment)
+	}
+	return levels
+}
+
+// runLouvain clusters the graph with the Louvain method once for each
+// repetition, returning the clustering of each one and the one with the
+// largest modularity. The clusterings have all levels, or only the
+// given one if level is positive
+func runLouvain(g *graph, resolution float64, selected int, seed int64, repeat int) ([]clustering, int, error) {
+	if resolution <= 0 {
+		return nil, 0, fmt.Errorf("resolution must be positive")
+	}
+	wg := newWeightedGraph(g)
+	seeds := repetitionSeeds(seed, repeat)
+	clusterings := make([]clustering, len(seeds))
+	errs := make([]error, len(seeds))
+	parameters := fmt.Sprintf("resolution=%v", resolution)
+	if selected > 0 {
+		parameters += fmt.Sprintf(" level=%v", selected)
+	}
+	parallel(len(seeds), func(i int) {
+		levels := louvain(wg, rand.New(rand.NewSource(int64(seeds[i]))), resolution)
+		ev := newEvaluator(g)
+		var hierarchy []level
+		for l := range levels {
+			if selected <= 0 || l+1 == selected {
+				mq := -ev.objectives(levels[l])[0]
+				hierarchy = append(hierarchy, level{l + 1, levels[l], mq, wg.modularity(levels[l], resolution)})
+			}
+		}
+		if len(hierarchy) == 0 {
+			errs[i] = fmt.Errorf("level %v not found, there are %v levels", selected, len(levels))
+			return
+		}
+		// the objectives are those of the innermost level written, the
+		// clusters that mq evaluates
+		clusters := hierarchy[0].clusters
+		clusterings[i] = clustering{
+			clusters:  clusters,
+			objective: ev.objectives(clusters),
+			meta: metadata{
+				algorithm:      "louvain",
+				seed:           seed,
+				repetition:     i,
+				repetitionSeed: seeds[i],
+				parameters:     parameters,
+				mdgHash:        g.hash,
+			},
+			hierarchy: hierarchy,
+		}
+	})
+	best := 0
+	for i := range clusterings {
+		if errs[i] != nil {
+			return nil, 0, errs[i]
+		}
+		last := func(c clustering) float64 { return c.hierarchy[len(c.hierarchy)-1].modularity }
+		if last(clusterings[i]) > last(clusterings[best]) {
+			best = i
+		}
+	}
+	return clusterings, best, nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// two triangles joined by a single edge
+const triangles = "a b\nb c\nc a\nd e\ne f\nf d\nc d\n"
+
+func TestLouvain(t *testing.T) {
+	g, err := readGraph(strings.NewReader(triangles))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wg := newWeightedGraph(g)
+	levels := louvain(wg, rand.New(rand.NewSource(1)), 1)
+	last := levels[len(levels)-1]
+	for v, want := range []int{0, 0, 0, 1, 1, 1} {
+		if (last[v] == last[0]) != (want == 0) {
+			t.Fatalf("expected the triangles as clusters, got %v", last)
+		}
+	}
+	// each triangle has 3 of the 7 edges inside and degrees summing to 7
+	want := 2 * (3.0/7 - (7.0/14)*(7.0/14))
+	if q := wg.modularity(last, 1); math.Abs(q-want) > 1e-9 {
+		t.Errorf("expected modularity %v, got %v", want, q)
+	}
+	// the aggregated graph keeps the inside weights as self-loops
+	a := wg.aggregate(last, 2)
+	if a.self[0] != 3 || a.self[1] != 3 || a.m != wg.m || a.degree[0] != 7 {
+		t.Errorf("unexpected aggregated graph %+v", a)
+	}
+}
+
+func TestLouvainDigraph(t *testing.T) {
+	g, err := readGraph(strings.NewReader(triangles))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := clustering{
+		clusters: []int{0, 0, 0, 0, 0, 0},
+		hierarchy: []level{
+			{1, []int{0, 0, 0, 1, 1, 1}, 0, 0},
+			{2, []int{0, 0, 0, 0, 0, 0}, 0, 0},
+		},
+	}
+	want := `subgraph cluster2_0 {
+subgraph cluster1_0 {
+"a";
+"b";
+"c";
+}
+subgraph cluster1_1 {
+"d";
+"e";
+"f";
+}
+}
+`
+	if d := c.digraph(g.names); !strings.Contains(d, want) {
+		t.Errorf("expected nested clusters, got\n%v", d)
+	}
+}
+
+func TestRunLouvainObjective(t *testing.T) {
+	// a ring of triangles, which are merged in the second level
+	var mdg strings.Builder
+	for i := 0; i < 12; i++ {
+		fmt.Fprintf(&mdg, "t%va t%vb\nt%vb t%vc\nt%vc t%va\nt%vc t%va\n", i, i, i, i, i, i, i, (i+1)%12)
+	}
+	g, err := readGraph(strings.NewReader(mdg.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	clusterings, best, err := runLouvain(g, 1, 0, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the objectives are the innermost level's, which mq evaluates
+	c := clusterings[best]
+	if len(c.hierarchy) < 2 {
+		t.Fatalf("expected several levels, got %v", c.hierarchy)
+	}
+	if c.objective[0] != -c.hierarchy[0].mq || !strings.Contains(c.header(), fmt.Sprintf("// mq=%v ", c.hierarchy[0].mq)) {
+		t.Errorf("expected the objectives of level 1, got %v and %v", c.objective, c.hierarchy)
+	}
+}
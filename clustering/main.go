@@ -1,31 +1,82 @@
 package main
 
 import (
-	"bufio"
 	"flag"
 	"fmt"
 	"log"
-	"math"
 	"os"
 	"runtime/pprof"
 	"strconv"
 	"strings"
 	"time"
-
-	"github.com/project-draco/moea"
-	"github.com/project-draco/moea/binary"
-	"github.com/project-draco/moea/integer"
-	"github.com/project-draco/moea/nsgaii"
 )
 
 func main() {
 	repeat := flag.Int("repeat", 0, "Repeat")
 	mono := flag.Bool("mono", false, "Mono-objective")
-	output := flag.String("output", "bestmq", "bestmq|paretto")
-	outputdir := flag.String("output-dir", "", "output dir")
+	encoding := flag.String("encoding", "binary", "binary|integer, for ga")
+	output := flag.String("output", "bestmq", "bestmq|knee|paretto")
+	outputdir := flag.String("output-dir", "", "output dir for paretto (default: current dir)")
+	manifest := flag.String("manifest", "json", "json|csv, format of the manifest of paretto")
 	cpuprofile := flag.String("cpuprofile", "", "write cpu profile to file")
 	memprofile := flag.String("memprofile", "", "write mem profile to file")
+	seed := flag.Int64("seed", 0, "random seed, from which each repetition's seed is derived (default: current time)")
+	algorithm := flag.String("algorithm", "ga", "ga|hill-climbing|louvain")
+	ascent := flag.String("ascent", "steepest", "steepest|next, for hill-climbing")
+	annealing := flag.Bool("annealing", false, "simulated annealing before hill-climbing")
+	temperature := flag.Float64("temperature", 0.1, "initial temperature of simulated annealing")
+	cooling := flag.Float64("cooling", 0.95, "temperature factor after each sweep of simulated annealing")
+	resolution := flag.Float64("resolution", 1, "resolution of louvain's modularity; higher values give smaller clusters")
+	level := flag.Int("level", 0, "write only this level of louvain's hierarchy, from 1, the finest (default: all levels, nested)")
+	constraintsfile := flag.String("constraints", "", "file with must-link and cannot-link constraints")
+	constraintMode := flag.String("constraint-mode", "repair", "repair|penalty, how ga enforces constraints; results of other algorithms are repaired, or only checked")
+	warm := flag.String("warm-start", "", "package|file|class|<dot file>, clustering from which ga and hill-climbing start")
+	warmFraction := flag.Float64("warm-fraction", 0.5, "fraction of ga's initial population seeded from the warm start")
+	stability := flag.Float64("stability", 0, "Turbo MQ that ga loses if all entities move from the warm start")
+	weights := flag.String("weights", "", "comma-separated weights of the MDGs given as arguments (default: all 1)")
+	layerObjectives := flag.Bool("layer-objectives", false, "add the Turbo MQ of each MDG given as argument to ga's objectives")
+	workers := flag.Int("workers", 0, "goroutines evaluating each repetition of ga (default: processors divided by repetitions)")
+	maxTime := flag.Duration("max-time", 0, "stop ga after this time, such as 12h (default: no limit)")
+	stagnation := flag.Int("stagnation", 0, "stop a repetition of ga after this many generations without improving Turbo MQ (default: no limit)")
+	checkpointfile := flag.String("checkpoint", "", "file where ga saves its population periodically and when it stops")
+	checkpointInterval := flag.Duration("checkpoint-interval", 10*time.Minute, "time between checkpoints")
+	resume := flag.Bool("resume", false, "continue ga from the checkpoint, with the same MDGs and options")
+	hierarchy := flag.Bool("hierarchy", false, "cluster the clusters again, level by level, while Turbo MQ improves, with ga or hill-climbing")
+	treefile := flag.String("tree", "", "write the clusters, nested by level, to this JSON file")
 	flag.Parse()
+	var ck *checkpoint
+	if *checkpointfile != "" || *resume {
+		if *algorithm != "ga" {
+			log.Fatal("checkpoints are only supported by ga")
+		}
+		if *checkpointfile == "" {
+			log.Fatal("resume requires a checkpoint")
+		}
+	}
+	if *resume {
+		var err error
+		if ck, err = readCheckpoint(*checkpointfile); err != nil {
+			log.Fatal(err)
+		}
+		// the checkpoint's seed, unless another is given
+		if *seed == 0 {
+			*seed = ck.Seed
+		}
+	}
+	if *seed == 0 {
+		*seed = time.Now().UTC().UnixNano()
+	}
+	if *hierarchy {
+		switch {
+		case *algorithm == "louvain":
+			log.Fatal("louvain is already hierarchical")
+		case *constraintsfile != "":
+			log.Fatal("constraints are not supported by hierarchy")
+		}
+	}
+	if (*hierarchy || *treefile != "") && *output == "paretto" {
+		log.Fatal("hierarchy and tree require bestmq or knee output")
+	}
 	if *cpuprofile != "" {
 		f, err := os.Create(*cpuprofile)
 		if err != nil {
@@ -37,210 +88,183 @@ func main() {
 			f.Close()
 		}()
 	}
-	type edge struct{ source, destination int }
-	graph := map[edge]float64{}
-	vertices := map[string]int{}
-	names := map[int]string{}
-	// read MDG from stdin
-	indexOf := func(name string) int {
-		if index, ok := vertices[name]; ok {
-			return index
-		}
-		index := len(vertices)
-		vertices[name] = index
-		names[index] = name
-		return index
-	}
-	scanner := bufio.NewScanner(os.Stdin)
-	for scanner.Scan() {
-		var arr []string
-		t := strings.TrimSpace(strings.Replace(scanner.Text(), "  ", " ", -1))
-		if len(t) == 0 {
-			continue
-		}
-		if strings.Index(t, "\t") != -1 {
-			arr = strings.Split(t, "\t")
-		} else {
-			arr = strings.Split(t, " ")
+	// read MDG from stdin, or blend the MDGs given as arguments
+	var g *graph
+	var err error
+	if flag.NArg() == 0 {
+		g, err = readGraph(os.Stdin)
+	} else {
+		w := make([]float64, flag.NArg())
+		for i := range w {
+			w[i] = 1
 		}
-		weigth := 1.0
-		if len(arr) > 2 {
-			w, err := strconv.Atoi(arr[2])
-			if err != nil {
-				fmt.Fprintln(os.Stderr, err)
-				return
+		if *weights != "" {
+			fields := strings.Split(*weights, ",")
+			if len(fields) != len(w) {
+				log.Fatalf("expected %v weights, got %v", len(w), len(fields))
+			}
+			for i, f := range fields {
+				if w[i], err = strconv.ParseFloat(f, 64); err != nil || w[i] < 0 {
+					log.Fatalf("invalid weight: %v", f)
+				}
 			}
-			weigth = float64(w)
 		}
-		graph[edge{indexOf(arr[0]), indexOf(arr[1])}] = weigth
+		g, err = readGraphs(flag.Args(), w)
 	}
-	if scanner.Err() != nil {
-		fmt.Fprintln(os.Stderr, scanner.Err())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
 		return
 	}
-	type edgeWithWeight struct {
-		edge   edge
-		weight float64
-	}
-	graphArr := make([]edgeWithWeight, len(graph))
-	i := 0
-	for k, v := range graph {
-		graphArr[i] = edgeWithWeight{edge: k, weight: v}
-		i++
-	}
-	// each individual has len(vertices) values representing the cluster each vertex belongs
-	lengths := make([]int, len(vertices))
-	bounds := make([]binary.Bound, len(vertices))
-	ibounds := make([]integer.Bound, len(vertices))
-	lbits := int(math.Ceil(math.Log2(float64(len(vertices))/2) - 0.5))
-	lbound := strings.Repeat("0", lbits)
-	ubound := fmt.Sprintf("%b", len(vertices)/2)
-	for i := 0; i < len(vertices); i++ {
-		lengths[i] = lbits
-		bounds[i] = binary.Bound{Min: lbound, Max: ubound}
-		ibounds[i] = integer.Bound{Min: 0, Max: len(vertices) / 2}
-	}
-	// set crossover probability according to Brian S. Mitchel (2002, p 93)
-	var cp float64
-	if len(vertices) <= 100 {
-		cp = 0.8
-	} else if len(vertices) >= 1000 {
-		cp = 1.0
-	} else {
-		cp = 0.8 + 0.2*(float64(len(vertices))-100)/899
+	if *layerObjectives && (*algorithm != "ga" || len(g.layers) < 2) {
+		log.Fatal("layer objectives require ga and several MDGs")
 	}
-	// set population size and max generations adapted from to Ivan Candela et al. (2016, p 93)
-	// except for the limit of 300, that is 150 in the referenced work, and the limit of 3000
-	// that is new
-	var ps, mg int
-	if len(vertices) > 10000 {
-		ps = len(vertices) / 4
-		mg = len(vertices)
-	} else if len(vertices) > 3000 {
-		ps = len(vertices) / 2
-		mg = 5 * len(vertices)
-	} else if len(vertices) > 300 {
-		ps = 1 * len(vertices)
-		mg = 20 * len(vertices)
-	} else {
-		ps = 2 * len(vertices)
-		mg = 50 * len(vertices)
+	var cs *constraints
+	if *constraintsfile != "" {
+		if *constraintMode != "repair" && *constraintMode != "penalty" {
+			log.Fatalf("invalid constraint mode: %v", *constraintMode)
+		}
+		if *algorithm == "louvain" {
+			log.Fatal("constraints are not supported by louvain")
+		}
+		f, err := os.Open(*constraintsfile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		cs, err = readConstraints(f, g)
+		f.Close()
+		if err != nil {
+			log.Fatal(err)
+		}
 	}
-	// This is the typical mutatation rate for binary encoding according to Brian S. Mitchel (2002, p 93)
-	mp := 16.0 / (math.Sqrt(float64(len(vertices))) * 1000)
-	var start time.Time
-	newConfig := func() *moea.Config {
-		// Objective function computes Turbo MQ metric according to Brian S. Mitchel (2002, pp 65-67)
-		α := make([]float64, len(vertices))
-		β := make([]float64, len(vertices))
-		c := make([]int64, len(vertices))
-		k := make([]float64, len(vertices))
-		objectiveFunc := func(individual moea.Individual) []float64 {
-			for i := range k {
-				k[i] = 0
-			}
-			for i := 0; i < len(vertices); i++ {
-				α[i] = 0
-				β[i] = 0
-				c[i] = individual.Value(i).(binary.BinaryString).Int().Int64()
-				k[c[i]]++
-			}
-			min, max, cc := math.MaxFloat64, 0.0, 0.0
-			for _, q := range k {
-				if q == 0 {
-					continue
-				}
-				cc++
-				if q < min {
-					min = q
-				}
-				if q > max {
-					max = q
-				}
-			}
-			f1, f2 := 0.0, 0.0
-			for _, e := range graphArr {
-				i := c[e.edge.source]
-				j := c[e.edge.destination]
-				if i == j {
-					α[i] += e.weight
-					f1++
-				} else {
-					β[i] += e.weight
-					β[j] += e.weight
-					f2 += 2
-				}
-			}
-			mq := 0.0
-			for i := 0; i < len(vertices); i++ {
-				if α[i] > 0 {
-					mq += 2 * α[i] / (2*α[i] + β[i])
-				}
+	var ws *warmStart
+	if *warm != "" {
+		if *algorithm == "louvain" {
+			log.Fatal("warm start is not supported by louvain")
+		}
+		switch *warm {
+		case "package", "file", "class":
+			ws, err = newLayoutWarmStart(g, *warm)
+		default:
+			var f *os.File
+			if f, err = os.Open(*warm); err == nil {
+				ws, err = readWarmStart(*warm, f, g)
+				f.Close()
 			}
-			return []float64{-mq, -f1, f2, -cc, max - min}
 		}
-		rng := moea.NewXorshiftWithSeed(uint32(time.Now().UTC().UnixNano()))
-		bp := binary.NewRandomBinaryPopulation(ps, lengths, nil /*bounds*/, rng)
-		// _ /*ip :*/ = integer.NewRandomIntegerPopulation(ps, len(vertices), ibounds, rng)
-		var selection moea.SelectionOperator
-		if *mono {
-			selection = &moea.TournamentSelection{TournamentSize: 10}
-		} else {
-			selection = &nsgaii.NsgaIISelection{}
+		if err != nil {
+			log.Fatal(err)
 		}
-		fmt.Fprintln(os.Stderr, "About to create config")
-		return &moea.Config{
-			Algorithm:             moea.NewSimpleAlgorithm(selection, &moea.FastMutation{}),
-			Population:            bp,
-			NumberOfObjectives:    5,
-			NumberOfValues:        len(vertices),
-			ObjectiveFunc:         objectiveFunc,
-			MaxGenerations:        mg,
-			CrossoverProbability:  cp,
-			MutationProbability:   mp,
-			RandomNumberGenerator: rng,
-			OnGenerationFunc: func(i int, r *moea.Result) {
-				if i == 0 {
-					fmt.Fprintf(os.Stderr, "start")
-				}
-				if (i+1)%100 == 0 {
-					fmt.Fprintf(os.Stderr, ".")
-				}
-				if (i+1)%1000 == 0 {
-					fmt.Fprintf(os.Stderr, "%s", time.Since(start).Round(100*time.Millisecond))
-				}
-				if (i+1)%5000 == 0 {
-					fmt.Println("")
-				}
-			},
+		fmt.Fprintf(os.Stderr, "%v of %v entities in the warm start\n", ws.known, len(g.names))
+	}
+	var clusterings []clustering
+	var best int
+	// recluster clusters the quotient graphs of the hierarchy with the
+	// same algorithm, without warm start
+	var recluster func(q *graph) ([]int, error)
+	switch *algorithm {
+	case "ga":
+		if *encoding != "binary" && *encoding != "integer" {
+			log.Fatalf("invalid encoding: %v", *encoding)
+		}
+		o := gaOptions{
+			mono:         *mono,
+			integer:      *encoding == "integer",
+			constraints:  cs,
+			penalty:      *constraintMode == "penalty",
+			warmStart:    ws,
+			warmFraction: *warmFraction,
+			stability:    *stability,
+			// each MDG's Turbo MQ as an objective
+			layerObjectives:    *layerObjectives,
+			workers:            *workers,
+			maxTime:            *maxTime,
+			stagnation:         *stagnation,
+			checkpoint:         *checkpointfile,
+			checkpointInterval: *checkpointInterval,
+			resume:             ck,
 		}
+		clusterings, best, err = runGA(g, o, *seed, *repeat)
+		recluster = gaReclusterer(o, *seed, *repeat)
+	case "hill-climbing":
+		if *ascent != "steepest" && *ascent != "next" {
+			log.Fatalf("invalid ascent: %v", *ascent)
+		}
+		o := hillClimbingOptions{
+			steepest:    *ascent == "steepest",
+			annealing:   *annealing,
+			temperature: *temperature,
+			cooling:     *cooling,
+			warmStart:   ws,
+		}
+		clusterings, best, err = runHillClimbing(g, o, *seed, *repeat)
+		recluster = hillClimbingReclusterer(o, *seed, *repeat)
+	case "louvain":
+		clusterings, best, err = runLouvain(g, *resolution, *level, *seed, *repeat)
+	default:
+		log.Fatalf("invalid algorithm: %v", *algorithm)
 	}
-	fmt.Fprintf(os.Stderr, "Max Generations: %v, Population Size: %v, Individual Size: %v, Variables: %v\n",
-		mg, ps, lbits*ps, len(lengths))
-	start = time.Now()
-	result, err := moea.RunRepeatedly(newConfig, *repeat)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		return
 	}
-	if *output == "bestmq" {
-		ind := result.Individuals[result.BestIndividualIndex]
-		fmt.Print(individualAsDigraph(ind, names))
-		fmt.Fprintln(os.Stderr, result.BestObjective[0])
-	} else {
-		if (*outputdir)[len(*outputdir)-1] != '/' {
-			*outputdir = *outputdir + "/"
+	if cs != nil {
+		cs.apply(g, clusterings, *constraintMode == "repair", *layerObjectives)
+		if *algorithm != "ga" {
+			// repairs may change which repetition is the best
+			best = 0
+			for i := range clusterings {
+				if clusterings[i].objective[0] < clusterings[best].objective[0] {
+					best = i
+				}
+			}
 		}
-		for i, ind := range result.Individuals {
-			g := individualAsDigraph(ind, names)
-			f, err := os.Create(fmt.Sprintf("%vgraph%v.dot", *outputdir, i))
-			if err != nil {
+		fmt.Fprintf(os.Stderr, "%v constraint violations\n", len(clusterings[best].violations))
+	}
+	if ws != nil {
+		ws.apply(g, clusterings, *layerObjectives)
+	}
+	if len(g.layers) > 0 {
+		ev := newEvaluator(g)
+		for i := range clusterings {
+			c := &clusterings[i]
+			c.layers = g.layers
+			c.layerMQ = ev.layers(c.clusters)
+			for j := range c.layerMQ {
+				c.layerMQ[j] = -c.layerMQ[j]
+			}
+		}
+	}
+	switch *output {
+	case "bestmq", "knee":
+		k := best
+		if *output == "knee" {
+			k = knee(clusterings, front(clusterings))
+		}
+		if *hierarchy {
+			if clusterings[k], err = hierarchical(g, clusterings[k], recluster); err != nil {
 				fmt.Fprintln(os.Stderr, err)
 				return
 			}
-			fmt.Fprint(f, g)
-			f.Close()
+			fmt.Fprintf(os.Stderr, "%v levels\n", len(clusterings[k].hierarchy))
 		}
+		fmt.Print(clusterings[k].digraph(g.names))
+		fmt.Fprintln(os.Stderr, clusterings[k].objective[0])
+		if *treefile != "" {
+			if err := writeTree(*treefile, g.names, clusterings[k]); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return
+			}
+		}
+	case "paretto":
+		if *manifest != "json" && *manifest != "csv" {
+			log.Fatalf("invalid manifest format: %v", *manifest)
+		}
+		if err := writeFront(*outputdir, *manifest, g.names, clusterings); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return
+		}
+	default:
+		log.Fatalf("invalid output: %v", *output)
 	}
 	if *memprofile != "" {
 		f, err := os.Create(*memprofile)
@@ -251,28 +275,3 @@ func main() {
 		f.Close()
 	}
 }
-
-func individualAsDigraph(
-	ind moea.IndividualResult,
-	names map[int]string,
-) string {
-	clusters := map[int64][]string{}
-	for i := 0; i < len(names); i++ {
-		c := ind.Values[i].(binary.BinaryString).Int().Int64()
-		if _, ok := clusters[c]; !ok {
-			clusters[c] = []string{}
-		}
-		clusters[c] = append(clusters[c], names[i])
-	}
-	var buf strings.Builder
-	buf.WriteString("digraph {\n")
-	for k, v := range clusters {
-		buf.WriteString(fmt.Sprintf("subgraph cluster%v {\n", k))
-		for _, n := range v {
-			buf.WriteString(fmt.Sprintf("\"%v\";\n", n))
-		}
-		buf.WriteString("}\n")
-	}
-	buf.WriteString("}\n")
-	return buf.String()
-}
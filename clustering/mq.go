@@ -0,0 +1,68 @@
+package main
+
+// evaluator computes the objectives of a clustering, given as the cluster
+// of each vertex, numbered from 0 to the number of vertices minus one.
+// It keeps the last clustering evaluated, so that the objectives of a
+// similar one are updated from the vertices that moved. It reuses its
+// buffers, so it must not be shared between goroutines
+type evaluator struct {
+	g    *graph
+	p    *partition
+	α, β []float64
+}
+
+func newEvaluator(g *graph) *evaluator {
+	n := len(g.names)
+	p := newPartition(g)
+	p.assign(make([]int, n))
+	return &evaluator{g: g, p: p, α: make([]float64, n), β: make([]float64, n)}
+}
+
+// objectives returns, all to be minimized, the negative Turbo MQ, the
+// negative number of intra-cluster edges, twice the number of
+// inter-cluster edges, the negative number of clusters and the difference
+// between the sizes of the largest and the smallest clusters.
+// Turbo MQ is computed according to Brian S. Mitchel (2002, pp 65-67)
+func (ev *evaluator) objectives(c []int) []float64 {
+	ev.p.update(c)
+	return ev.p.objectives()
+}
+
+// objectivesWithLayers returns the objectives of the clustering followed,
+// if layerObjectives is set, by the negative Turbo MQ of each layer, as in
+// ga with layer objectives
+func (ev *evaluator) objectivesWithLayers(c []int, layerObjectives bool) []float64 {
+	objective := ev.objectives(c)
+	if layerObjectives {
+		objective = append(objective, ev.layers(c)...)
+	}
+	return objective
+}
+
+// layers returns the negative Turbo MQ of the clustering in each layer
+func (ev *evaluator) layers(c []int) []float64 {
+	result := make([]float64, len(ev.g.layers))
+	for l, layer := range ev.g.layers {
+		α, β := ev.α, ev.β
+		for i := range α {
+			α[i] = 0
+			β[i] = 0
+		}
+		for _, e := range layer.edges {
+			i := c[e.edge.source]
+			j := c[e.edge.destination]
+			if i == j {
+				α[i] += e.weight
+			} else {
+				β[i] += e.weight
+				β[j] += e.weight
+			}
+		}
+		for i := range α {
+			if α[i] > 0 {
+				result[l] -= 2 * α[i] / (2*α[i] + β[i])
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+// front returns the indexes of the clusterings whose objectives no other
+// clustering dominates, sorted by objectives and then by index. Of
+// clusterings with the same clusters, only the first is kept
+func front(clusterings []clustering) []int {
+	seen := map[string]bool{}
+	var result []int
+	for i := range clusterings {
+		dominated := false
+		for j := range clusterings {
+			if i != j && dominates(clusterings[j].objective, clusterings[i].objective) {
+				dominated = true
+				break
+			}
+		}
+		if dominated {
+			continue
+		}
+		key := clusterings[i].canonical()
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, i)
+	}
+	sort.SliceStable(result, func(a, b int) bool {
+		oa, ob := clusterings[result[a]].objective, clusterings[result[b]].objective
+		for k := range oa {
+			if oa[k] != ob[k] {
+				return oa[k] < ob[k]
+			}
+		}
+		return false
+	})
+	return result
+}
+
+// dominates tells whether the objectives a are no worse than b in all
+// objectives and better in at least one
+func dominates(a, b []float64) bool {
+	better := false
+	for k := range a {
+		if a[k] > b[k] {
+			return false
+		}
+		if a[k] < b[k] {
+			better = true
+		}
+	}
+	return better
+}
+
+// canonical returns the clusters numbered in order of appearance, which
+// is the same for equal clusterings
+func (c clustering) canonical() string {
+	number := map[int]int{}
+	b := make([]byte, 0, 4*len(c.clusters))
+	for _, k := range c.clusters {
+		if _, ok := number[k]; !ok {
+			number[k] = len(number)
+		}
+		b = strconv.AppendInt(b, int64(number[k]), 10)
+		b = append(b, ',')
+	}
+	return string(b)
+}
+
+// knee returns the member of the front closest to the ideal point, whose
+// objectives are the best in the front, with each objective normalized
+// by its range in the front. Ties are broken by the order of the front
+func knee(clusterings []clustering, front []int) int {
+	if len(front) == 0 {
+		return -1
+	}
+	n := len(clusterings[front[0]].objective)
+	min := make([]float64, n)
+	max := make([]float64, n)
+	for k := range min {
+		min[k], max[k] = math.MaxFloat64, -math.MaxFloat64
+		for _, i := range front {
+			min[k] = math.Min(min[k], clusterings[i].objective[k])
+			max[k] = math.Max(max[k], clusterings[i].objective[k])
+		}
+	}
+	best, bestDistance := front[0], math.MaxFloat64
+	for _, i := range front {
+		distance := 0.0
+		for k, o := range clusterings[i].objective {
+			if max[k] > min[k] {
+				d := (o - min[k]) / (max[k] - min[k])
+				distance += d * d
+			}
+		}
+		if distance < bestDistance {
+			best, bestDistance = i, distance
+		}
+	}
+	return best
+}
+
+type manifestEntry struct {
+	File             string  `json:"file"`
+	MQ               float64 `json:"mq"`
+	IntraEdges       float64 `json:"intraEdges"`
+	InterEdges       float64 `json:"interEdges"`
+	Clusters         float64 `json:"clusters"`
+	ClusterSizeRange float64 `json:"clusterSizeRange"`
+	Best             bool    `json:"best"`
+	Knee             bool    `json:"knee"`
+}
+
+// writeFront writes each member of the front as graph<i>.dot, numbered in
+// the order of the front, into dir, which is created if needed, along
+// with a manifest of their objectives in JSON or CSV
+func writeFront(dir, format string, names []string, clusterings []clustering) error {
+	if dir == "" {
+		dir = "."
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	members := front(clusterings)
+	k := knee(clusterings, members)
+	var entries []manifestEntry
+	for j, i := range members {
+		c := clusterings[i]
+		file := fmt.Sprintf("graph%v.dot", j)
+		if err := ioutil.WriteFile(filepath.Join(dir, file), []byte(c.digraph(names)), 0644); err != nil {
+			return err
+		}
+		o := c.objective
+		entries = append(entries, manifestEntry{
+			File:             file,
+			MQ:               -o[0],
+			IntraEdges:       -o[1],
+			InterEdges:       o[2] / 2,
+			Clusters:         -o[3],
+			ClusterSizeRange: o[4],
+			// the front is sorted by the first objective
+			Best: j == 0,
+			Knee: i == k,
+		})
+	}
+	f, err := os.Create(filepath.Join(dir, "manifest."+format))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if format == "csv" {
+		return writeManifestCSV(f, entries)
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	return enc.Encode(entries)
+}
+
+func writeManifestCSV(w io.Writer, entries []manifestEntry) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"file", "mq", "intraEdges", "interEdges", "clusters", "clusterSizeRange", "best", "knee"})
+	f := func(v float64) string { return strconv.FormatFloat(v, 'g', -1, 64) }
+	for _, e := range entries {
+		cw.Write([]string{e.File, f(e.MQ), f(e.IntraEdges), f(e.InterEdges), f(e.Clusters), f(e.ClusterSizeRange),
+			strconv.FormatBool(e.Best), strconv.FormatBool(e.Knee)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
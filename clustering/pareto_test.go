@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFront(t *testing.T) {
+	clusterings := []clustering{
+		{clusters: []int{0, 0, 1}, objective: []float64{-2, 0, 0, 0, 0}},
+		{clusters: []int{0, 1, 1}, objective: []float64{-1, -5, 0, 0, 0}},
+		// dominated by the first
+		{clusters: []int{0, 1, 2}, objective: []float64{-1, 0, 1, 0, 0}},
+		// the same clusters as the first, numbered differently
+		{clusters: []int{3, 3, 0}, objective: []float64{-2, 0, 0, 0, 0}},
+		{clusters: []int{0, 0, 0}, objective: []float64{-1.5, -3, 0, 0, 0}},
+	}
+	f := front(clusterings)
+	if want := []int{0, 4, 1}; !reflect.DeepEqual(f, want) {
+		t.Fatalf("expected front %v, got %v", want, f)
+	}
+	if k := knee(clusterings, f); k != 4 {
+		t.Errorf("expected knee 4, got %v", k)
+	}
+}
+
+func TestWriteFront(t *testing.T) {
+	dir, err := ioutil.TempDir("", "clustering")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	clusterings := []clustering{
+		{clusters: []int{5, 5, 2}, objective: []float64{-1, -1, 2, -2, 1}},
+		{clusters: []int{1, 0, 0}, objective: []float64{-0.5, -2, 0, -1, 0}},
+	}
+	out := filepath.Join(dir, "front")
+	if err := writeFront(out, "json", []string{"a", "b", "c"}, clusterings); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(out, "manifest.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entries []manifestEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].MQ != 1 || entries[0].InterEdges != 1 || !entries[0].Best || entries[1].Clusters != 1 {
+		t.Errorf("unexpected manifest %+v", entries)
+	}
+	dot, err := ioutil.ReadFile(filepath.Join(out, "graph0.dot"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// clusters are numbered in order of appearance
+	if !strings.Contains(string(dot), "subgraph cluster0 {\n\"a\";\n\"b\";\n}\nsubgraph cluster1 {\n\"c\";") {
+		t.Errorf("unexpected DOT\n%s", dot)
+	}
+	if err := writeFront(out, "csv", []string{"a", "b", "c"}, clusterings); err != nil {
+		t.Fatal(err)
+	}
+	csv, _ := ioutil.ReadFile(filepath.Join(out, "manifest.csv"))
+	if lines := strings.Split(strings.TrimSpace(string(csv)), "\n"); len(lines) != 3 || lines[1] != "graph0.dot,1,1,1,2,1,true,false" {
+		t.Errorf("unexpected CSV manifest\n%s", csv)
+	}
+}
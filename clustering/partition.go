@@ -0,0 +1,294 @@
+package main
+
+import "math"
+
+// arc is an edge seen from one of its vertices, with the weights and the
+// number of the edges in both directions
+type arc struct {
+	vertex int
+	weight int64
+	edges  int
+}
+
+// pair is the edges between two vertices, in both directions
+type pair struct {
+	u, v   int
+	weight int64
+	edges  int
+}
+
+// partition is a clustering of a graph that keeps the intra-cluster (α)
+// and inter-cluster (β) weights and the cluster factor of each cluster,
+// the number of intra- and inter-cluster edges and the cluster sizes, so
+// that moving a vertex updates Turbo MQ and the other objectives from the
+// vertex's neighbors only. Weights and cluster factors are fixed-point
+// integers, so that the objectives of a clustering do not depend on the
+// moves that led to it. It is shared by the genetic algorithm, through
+// evaluator, and by the local searches
+type partition struct {
+	adjacency [][]arc
+	// pairs has each pair of adjacent vertices once, for assign
+	pairs     []pair
+	self      []int64
+	selfEdges []int
+	// total is the weight of the edges of each vertex, but self-loops
+	total   []int64
+	cluster []int
+	size    []int
+	α, β    []int64
+	factor  []int64
+	// sum is the sum of the cluster factors, Turbo MQ times mqUnit
+	sum          int64
+	intra, inter int
+	// clusters is the number of non-empty clusters, count the number of
+	// clusters of each size, and min and max the smallest and the largest
+	// sizes of the non-empty clusters
+	clusters, min, max int
+	count              []int
+	// weightUnit and mqUnit are the fixed-point scales of weights and
+	// cluster factors, leaving room for their sums
+	weightUnit, mqUnit float64
+	// weights and touched are buffers of weightsTo, and moved of update
+	weights []int64
+	touched []int
+	moved   []int
+}
+
+func newPartition(g *graph) *partition {
+	n := len(g.names)
+	p := &partition{
+		adjacency: make([][]arc, n),
+		self:      make([]int64, n),
+		selfEdges: make([]int, n),
+		total:     make([]int64, n),
+		cluster:   make([]int, n),
+		size:      make([]int, n),
+		α:         make([]int64, n),
+		β:         make([]int64, n),
+		factor:    make([]int64, n),
+		count:     make([]int, n+1),
+		weights:   make([]int64, n),
+	}
+	w := 0.0
+	for _, e := range g.edges {
+		w += math.Abs(e.weight)
+	}
+	// 2α+β is at most four times the total weight, and Turbo MQ at most n
+	_, exp := math.Frexp(w)
+	p.weightUnit = math.Ldexp(1, 60-exp)
+	_, exp = math.Frexp(float64(n))
+	p.mqUnit = math.Ldexp(1, 62-exp)
+	index := make([]map[int]int, n)
+	for _, e := range g.edges {
+		u, v := e.edge.source, e.edge.destination
+		weight := int64(math.Round(e.weight * p.weightUnit))
+		if u == v {
+			p.self[u] += weight
+			p.selfEdges[u]++
+			continue
+		}
+		for _, pair := range [][2]int{{u, v}, {v, u}} {
+			if index[pair[0]] == nil {
+				index[pair[0]] = map[int]int{}
+			}
+			i, ok := index[pair[0]][pair[1]]
+			if !ok {
+				i = len(p.adjacency[pair[0]])
+				index[pair[0]][pair[1]] = i
+				p.adjacency[pair[0]] = append(p.adjacency[pair[0]], arc{vertex: pair[1]})
+			}
+			p.adjacency[pair[0]][i].weight += weight
+			p.adjacency[pair[0]][i].edges++
+		}
+		p.total[u] += weight
+		p.total[v] += weight
+	}
+	for u := range p.adjacency {
+		for _, a := range p.adjacency[u] {
+			if a.vertex > u {
+				p.pairs = append(p.pairs, pair{u, a.vertex, a.weight, a.edges})
+			}
+		}
+	}
+	return p
+}
+
+// assign sets the clusters, numbered from 0 to the number of vertices
+// minus one, and computes the weights and objectives
+func (p *partition) assign(clusters []int) {
+	copy(p.cluster, clusters)
+	for k := range p.size {
+		p.size[k], p.α[k], p.β[k], p.factor[k] = 0, 0, 0, 0
+	}
+	p.intra, p.inter = 0, 0
+	for v, k := range p.cluster {
+		p.size[k]++
+		p.α[k] += p.self[v]
+		p.intra += p.selfEdges[v]
+	}
+	for _, e := range p.pairs {
+		if k, l := p.cluster[e.u], p.cluster[e.v]; k == l {
+			p.α[k] += e.weight
+			p.intra += e.edges
+		} else {
+			p.β[k] += e.weight
+			p.β[l] += e.weight
+			p.inter += e.edges
+		}
+	}
+	p.sum, p.clusters = 0, 0
+	for i := range p.count {
+		p.count[i] = 0
+	}
+	p.min, p.max = len(p.count), 0
+	for k, s := range p.size {
+		p.factor[k] = p.cf(p.α[k], p.β[k])
+		p.sum += p.factor[k]
+		if s == 0 {
+			continue
+		}
+		p.clusters++
+		p.count[s]++
+		if s < p.min {
+			p.min = s
+		}
+		if s > p.max {
+			p.max = s
+		}
+	}
+}
+
+// update sets the clusters, moving the vertices whose clusters changed,
+// or assigning all of them if that is cheaper
+func (p *partition) update(clusters []int) {
+	p.moved = p.moved[:0]
+	cost := 0
+	for v, k := range clusters {
+		if p.cluster[v] == k {
+			continue
+		}
+		// moving a vertex costs about twice as much per arc as assign per pair
+		cost += 2*len(p.adjacency[v]) + 1
+		if cost > len(p.pairs)+len(p.cluster) {
+			p.assign(clusters)
+			return
+		}
+		p.moved = append(p.moved, v)
+	}
+	for _, v := range p.moved {
+		p.move(v, clusters[v])
+	}
+}
+
+// cf returns the cluster factor of Turbo MQ, in units of mqUnit
+func (p *partition) cf(α, β int64) int64 {
+	if α <= 0 {
+		return 0
+	}
+	return int64(float64(2*α)/float64(2*α+β)*p.mqUnit + 0.5)
+}
+
+// mq returns Turbo MQ
+func (p *partition) mq() float64 {
+	return float64(p.sum) / p.mqUnit
+}
+
+// objectives returns the objectives of evaluator.objectives
+func (p *partition) objectives() []float64 {
+	return []float64{-p.mq(), -float64(p.intra), 2 * float64(p.inter), -float64(p.clusters), float64(p.max - p.min)}
+}
+
+// weightsTo sets the weights from v to each neighbor cluster, returning
+// the clusters touched, which must be reset by resetWeights
+func (p *partition) weightsTo(v int) []int {
+	p.touched = p.touched[:0]
+	for _, a := range p.adjacency[v] {
+		k := p.cluster[a.vertex]
+		if p.weights[k] == 0 {
+			p.touched = append(p.touched, k)
+		}
+		p.weights[k] += a.weight
+	}
+	return p.touched
+}
+
+func (p *partition) resetWeights() {
+	for _, k := range p.touched {
+		p.weights[k] = 0
+	}
+}
+
+// delta returns the change in Turbo MQ of moving v to cluster b.
+// weightsTo(v) must have been called
+func (p *partition) delta(v, b int) float64 {
+	a := p.cluster[v]
+	wa, wb := p.weights[a], p.weights[b]
+	d := p.cf(p.α[a]-wa-p.self[v], p.β[a]-(p.total[v]-wa)+wa) +
+		p.cf(p.α[b]+wb+p.self[v], p.β[b]-wb+(p.total[v]-wb)) - p.factor[a] - p.factor[b]
+	return float64(d) / p.mqUnit
+}
+
+// move moves v to cluster b, updating the weights and objectives
+func (p *partition) move(v, b int) {
+	a := p.cluster[v]
+	if a == b {
+		return
+	}
+	var wa, wb int64
+	var ea, eb int
+	for _, nb := range p.adjacency[v] {
+		switch p.cluster[nb.vertex] {
+		case a:
+			wa += nb.weight
+			ea += nb.edges
+		case b:
+			wb += nb.weight
+			eb += nb.edges
+		}
+	}
+	p.setWeights(a, p.α[a]-wa-p.self[v], p.β[a]-(p.total[v]-wa)+wa)
+	p.setWeights(b, p.α[b]+wb+p.self[v], p.β[b]-wb+(p.total[v]-wb))
+	p.intra += eb - ea
+	p.inter += ea - eb
+	p.cluster[v] = b
+	p.resize(a, -1)
+	p.resize(b, 1)
+}
+
+func (p *partition) setWeights(k int, α, β int64) {
+	p.α[k], p.β[k] = α, β
+	f := p.cf(α, β)
+	p.sum += f - p.factor[k]
+	p.factor[k] = f
+}
+
+// resize changes the size of cluster k by d, keeping the number of
+// clusters of each size, from which the smallest and the largest sizes
+// are found
+func (p *partition) resize(k, d int) {
+	s := p.size[k]
+	if s > 0 {
+		p.count[s]--
+	} else {
+		p.clusters++
+	}
+	s += d
+	p.size[k] = s
+	if s > 0 {
+		p.count[s]++
+		if s < p.min {
+			p.min = s
+		}
+		if s > p.max {
+			p.max = s
+		}
+	} else {
+		p.clusters--
+	}
+	for p.max > 0 && p.count[p.max] == 0 {
+		p.max--
+	}
+	for p.min < p.max && p.count[p.min] == 0 {
+		p.min++
+	}
+}
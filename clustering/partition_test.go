@@ -0,0 +1,110 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// referenceObjectives computes the objectives from all edges, as
+// evaluator did before it kept a partition
+func referenceObjectives(g *graph, c []int) []float64 {
+	n := len(g.names)
+	α, β, k := make([]float64, n), make([]float64, n), make([]float64, n)
+	for i := range c {
+		k[c[i]]++
+	}
+	min, max, cc := math.MaxFloat64, 0.0, 0.0
+	for _, q := range k {
+		if q == 0 {
+			continue
+		}
+		cc++
+		min = math.Min(min, q)
+		max = math.Max(max, q)
+	}
+	f1, f2 := 0.0, 0.0
+	for _, e := range g.edges {
+		i, j := c[e.edge.source], c[e.edge.destination]
+		if i == j {
+			α[i] += e.weight
+			f1++
+		} else {
+			β[i] += e.weight
+			β[j] += e.weight
+			f2 += 2
+		}
+	}
+	mq := 0.0
+	for i := range α {
+		if α[i] > 0 {
+			mq += 2 * α[i] / (2*α[i] + β[i])
+		}
+	}
+	return []float64{-mq, -f1, f2, -cc, max - min}
+}
+
+func TestPartition(t *testing.T) {
+	g := randomGraph(t, 50, 150, 5)
+	// a self-loop and an edge in both directions
+	g.edges = append(g.edges, edgeWithWeight{edge{3, 3}, 2.5}, edgeWithWeight{edge{1, 0}, 0.1}, edgeWithWeight{edge{0, 1}, 0.2})
+	n := len(g.names)
+	rng := rand.New(rand.NewSource(1))
+	c := make([]int, n)
+	for v := range c {
+		c[v] = rng.Intn(n / 2)
+	}
+	ev := newEvaluator(g)
+	for i := 0; i < 500; i++ {
+		// move a few vertices, and sometimes all of them
+		moves := 1 + rng.Intn(3)
+		if i%50 == 0 {
+			moves = n
+		}
+		for j := 0; j < moves; j++ {
+			c[rng.Intn(n)] = rng.Intn(n)
+		}
+		got, want := ev.objectives(c), referenceObjectives(g, c)
+		for k := range want {
+			if math.Abs(got[k]-want[k]) > 1e-9 {
+				t.Fatalf("%v: expected %v, got %v", i, want, got)
+			}
+		}
+		// the objectives do not depend on the moves that led to them
+		if fresh := newEvaluator(g).objectives(c); !reflect.DeepEqual(fresh, got) {
+			t.Fatalf("%v: expected %v from scratch, got %v", i, fresh, got)
+		}
+	}
+}
+
+// benchmarkObjectives evaluates clusterings of a 10k-vertex MDG that
+// differ by moved vertices, as mutation does
+func benchmarkObjectives(b *testing.B, moved int, incremental bool) {
+	g := randomGraph(b, 10000, 40000, 6)
+	n := len(g.names)
+	rng := rand.New(rand.NewSource(1))
+	c := make([]int, n)
+	for v := range c {
+		c[v] = rng.Intn(n / 2)
+	}
+	ev := newEvaluator(g)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j := 0; j < moved; j++ {
+			c[rng.Intn(n)] = rng.Intn(n / 2)
+		}
+		if !incremental {
+			ev.p.assign(c)
+		}
+		ev.objectives(c)
+	}
+}
+
+func BenchmarkObjectivesFromScratch(b *testing.B) { benchmarkObjectives(b, 10, false) }
+
+func BenchmarkObjectivesIncremental1(b *testing.B) { benchmarkObjectives(b, 1, true) }
+
+func BenchmarkObjectivesIncremental10(b *testing.B) { benchmarkObjectives(b, 10, true) }
+
+func BenchmarkObjectivesIncremental1000(b *testing.B) { benchmarkObjectives(b, 1000, true) }
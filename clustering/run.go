@@ -0,0 +1,239 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/project-draco/moea"
+)
+
+// repetitionSeeds derives the seed of each repetition from the given seed
+// using the SplitMix64 finalizer, so that repetitions are independent but
+// reproducible. A single run is done when repeat is less than 2
+func repetitionSeeds(seed int64, repeat int) []uint32 {
+	if repeat < 2 {
+		repeat = 1
+	}
+	seeds := make([]uint32, repeat)
+	for i := range seeds {
+		z := uint64(seed) + uint64(i+1)*0x9e3779b97f4a7c15
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		z ^= z >> 31
+		seeds[i] = uint32(z)
+		// xorshift never leaves the zero state
+		if seeds[i] == 0 {
+			seeds[i] = 1
+		}
+	}
+	return seeds
+}
+
+// parallel calls f for each index from 0 to n-1, in as many goroutines
+// as processors
+func parallel(n int, f func(i int)) {
+	parallelWorkers(runtime.GOMAXPROCS(0), n, func(_, i int) { f(i) })
+}
+
+// parallelWorkers calls f for each index from 0 to n-1 in the given
+// number of goroutines, along with the goroutine's worker number, from 0,
+// so that each worker can have its own buffers
+func parallelWorkers(workers, n int, f func(worker, i int)) {
+	ch := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < n; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for j := range ch {
+				f(w, j)
+			}
+		}(w)
+	}
+	for i := 0; i < n; i++ {
+		ch <- i
+	}
+	close(ch)
+	wg.Wait()
+}
+
+// runRepeatedly runs the algorithm once for each seed, in parallel, and
+// returns the result with the best first objective along with its index.
+// Ties are broken by the lowest index, so the result does not depend on
+// the order in which runs finish
+func runRepeatedly(run func(repetition int, seed uint32) (*moea.Result, error), seeds []uint32) (*moea.Result, int, error) {
+	results := make([]*moea.Result, len(seeds))
+	errs := make([]error, len(seeds))
+	parallel(len(seeds), func(i int) {
+		results[i], errs[i] = run(i, seeds[i])
+	})
+	best := 0
+	for i := range results {
+		if errs[i] != nil {
+			return nil, 0, errs[i]
+		}
+		if results[i].BestObjective[0] < results[best].BestObjective[0] {
+			best = i
+		}
+	}
+	return results[best], best, nil
+}
+
+// metadata records how a clustering was computed, so that it can be
+// reproduced
+type metadata struct {
+	algorithm      string
+	seed           int64
+	repetition     int
+	repetitionSeed uint32
+	// parameters are the algorithm's parameters, as key=value pairs
+	parameters string
+	mdgHash    string
+}
+
+// level is a clustering at one level of a hierarchy, numbered from 1,
+// the finest, with its Turbo MQ and modularity
+type level struct {
+	number     int
+	clusters   []int
+	mq         float64
+	modularity float64
+}
+
+// clustering is the cluster of each vertex, along with its objectives
+// and how it was computed. Hierarchical clusterings also have their
+// levels, the last one being clusters for louvain, which merges clusters
+// while modularity improves, and the first one for the others
+type clustering struct {
+	clusters  []int
+	objective []float64
+	meta      metadata
+	hierarchy []level
+	// constrained is set if constraints were given, in which case
+	// violations are the constraints not satisfied
+	constrained bool
+	violations  []string
+	// warmStarted is set if the algorithm started from a warm start, in
+	// which case moved is the number of vertices moved from it
+	warmStarted bool
+	moved       int
+	// layerMQ is the Turbo MQ of the clustering in each layer of the graph
+	// in layers, if several MDGs were clustered together
+	layers  []layer
+	layerMQ []float64
+}
+
+// header returns DOT comments with the metadata and the objective values
+// of the clustering
+func (c clustering) header() string {
+	var buf strings.Builder
+	m := c.meta
+	fmt.Fprintf(&buf, "// algorithm=%v seed=%v repetition=%v repetition-seed=%v\n",
+		m.algorithm, m.seed, m.repetition, m.repetitionSeed)
+	if m.parameters != "" {
+		fmt.Fprintf(&buf, "// %v\n", m.parameters)
+	}
+	fmt.Fprintf(&buf, "// mdg-sha256=%v\n", m.mdgHash)
+	for _, l := range c.hierarchy {
+		fmt.Fprintf(&buf, "// level=%v clusters=%v mq=%v modularity=%v\n", l.number, count(l.clusters), l.mq, l.modularity)
+	}
+	if c.constrained {
+		fmt.Fprintf(&buf, "// violations=%v\n", len(c.violations))
+		for _, v := range c.violations {
+			fmt.Fprintf(&buf, "// violation: %v\n", v)
+		}
+	}
+	for i, l := range c.layers {
+		fmt.Fprintf(&buf, "// graph=%v weight=%v mq=%v\n", l.name, l.weight, c.layerMQ[i])
+	}
+	if c.warmStarted {
+		fmt.Fprintf(&buf, "// moved=%v\n", c.moved)
+	}
+	if o := c.objective; len(o) >= 5 {
+		fmt.Fprintf(&buf, "// mq=%v intra-edges=%v inter-edges=%v clusters=%v cluster-size-range=%v\n",
+			-o[0], -o[1], o[2]/2, -o[3], o[4])
+	}
+	return buf.String()
+}
+
+// digraph returns the clustering in DOT format, each cluster as a
+// subgraph, preceded by the header. Clusters of hierarchical clusterings
+// are nested in the clusters of the next level, and named after their
+// level
+func (c clustering) digraph(names []string) string {
+	var buf strings.Builder
+	buf.WriteString(c.header())
+	buf.WriteString("digraph {\n")
+	if len(c.hierarchy) > 1 {
+		c.writeLevel(&buf, names, len(c.hierarchy)-1, nil)
+	} else {
+		m := members(c.clusters, nil)
+		keys := sortedKeys(m)
+		// clusters are numbered in order of appearance, so that equal
+		// clusterings have the same DOT
+		sort.Slice(keys, func(i, j int) bool { return m[keys[i]][0] < m[keys[j]][0] })
+		for i, k := range keys {
+			buf.WriteString(fmt.Sprintf("subgraph cluster%v {\n", i))
+			for _, v := range m[k] {
+				buf.WriteString(fmt.Sprintf("\"%v\";\n", names[v]))
+			}
+			buf.WriteString("}\n")
+		}
+	}
+	buf.WriteString("}\n")
+	return buf.String()
+}
+
+// writeLevel writes the clusters of level l having the given vertices
+// (all, if nil) and, inside each one, its clusters of the previous level
+func (c clustering) writeLevel(buf *strings.Builder, names []string, l int, vertices []int) {
+	h := c.hierarchy[l]
+	m := members(h.clusters, vertices)
+	for _, k := range sortedKeys(m) {
+		buf.WriteString(fmt.Sprintf("subgraph cluster%v_%v {\n", h.number, k))
+		if l > 0 {
+			c.writeLevel(buf, names, l-1, m[k])
+		} else {
+			for _, v := range m[k] {
+				buf.WriteString(fmt.Sprintf("\"%v\";\n", names[v]))
+			}
+		}
+		buf.WriteString("}\n")
+	}
+}
+
+// members returns the given vertices (all, if nil) of each cluster, in
+// order
+func members(clusters []int, vertices []int) map[int][]int {
+	m := map[int][]int{}
+	if vertices == nil {
+		for v, k := range clusters {
+			m[k] = append(m[k], v)
+		}
+		return m
+	}
+	for _, v := range vertices {
+		m[clusters[v]] = append(m[clusters[v]], v)
+	}
+	return m
+}
+
+func sortedKeys(m map[int][]int) []int {
+	var keys []int
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
+
+func count(clusters []int) int {
+	seen := map[int]bool{}
+	for _, k := range clusters {
+		seen[k] = true
+	}
+	return len(seen)
+}
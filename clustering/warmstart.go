@@ -0,0 +1,210 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"sort"
+	"strings"
+
+	"github.com/awalterschulze/gographviz"
+	"github.com/project-draco/moea"
+	"github.com/project-draco/tools/historage"
+)
+
+// warmStart is a clustering from which algorithms start instead of a
+// random one, such as the current layout or the result of a previous run
+type warmStart struct {
+	// name is the layout or the DOT file
+	name string
+	// clusters has the cluster of each vertex, or -1 for vertices the
+	// warm start does not have
+	clusters []int
+	known    int
+}
+
+// check returns an error unless the warm start has a cluster, or -1, for
+// each vertex of the graph, since it is of another graph otherwise
+func (ws *warmStart) check(g *graph) error {
+	if len(ws.clusters) != len(g.names) {
+		return fmt.Errorf("warm start %v has %v vertices, but the graph has %v", ws.name, len(ws.clusters), len(g.names))
+	}
+	return nil
+}
+
+// apply sets the vertices of each clustering moved from the warm start,
+// and recomputes its objectives, which may include the stability penalty,
+// along with the layers' if they are objectives
+func (ws *warmStart) apply(g *graph, clusterings []clustering, layerObjectives bool) {
+	ev := newEvaluator(g)
+	for i := range clusterings {
+		c := &clusterings[i]
+		c.objective = ev.objectivesWithLayers(c.clusters, layerObjectives)
+		c.warmStarted = true
+		c.moved = ws.moved(c.clusters)
+	}
+}
+
+// perturbation is the probability of a vertex keeping a random cluster in
+// all but the first warm-started individual or repetition, so that they
+// are not all the same
+const perturbation = 0.1
+
+// newLayoutWarmStart clusters the vertices by package, file or class,
+// from the Historage names of the entities, such as src_p for
+// src_p_A.java/[CN]/A/[MT]/m() by package
+func newLayoutWarmStart(g *graph, layout string) (*warmStart, error) {
+	var module func(string) string
+	switch layout {
+	case "package":
+		module = historage.Package
+	case "file":
+		module = historage.File
+	case "class":
+		module = historage.Class
+	default:
+		return nil, fmt.Errorf("unknown layout %v", layout)
+	}
+	modules := make([]string, len(g.names))
+	for v, name := range g.names {
+		modules[v] = module(name)
+	}
+	return newWarmStart(layout, modules), nil
+}
+
+// readWarmStart clusters the vertices as in a DOT file written by a
+// previous run. Vertices in nested clusters belong to the innermost one
+func readWarmStart(name string, r io.Reader, g *graph) (*warmStart, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	ast, err := gographviz.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	dot := gographviz.NewGraph()
+	if err := gographviz.Analyse(ast, dot); err != nil {
+		return nil, err
+	}
+	modules := make([]string, len(g.names))
+	for cluster, nodes := range dot.Relations.ParentToChildren {
+		if !strings.HasPrefix(cluster, "cluster") {
+			continue
+		}
+		for node := range nodes {
+			if v, ok := g.vertices[strings.Trim(node, `"`)]; ok {
+				modules[v] = cluster
+			}
+		}
+	}
+	return newWarmStart(name, modules), nil
+}
+
+// newWarmStart numbers the modules in order of appearance, ignoring empty
+// ones
+func newWarmStart(name string, modules []string) *warmStart {
+	ws := &warmStart{name: name, clusters: make([]int, len(modules))}
+	number := map[string]int{}
+	for v, m := range modules {
+		if m == "" {
+			ws.clusters[v] = -1
+			continue
+		}
+		if _, ok := number[m]; !ok {
+			number[m] = len(number)
+		}
+		ws.clusters[v] = number[m]
+		ws.known++
+	}
+	return ws
+}
+
+// seed returns the warm start with clusters from 0 to max, keeping the
+// given clusters of unknown vertices and, if perturb is set, of random
+// vertices
+func (ws *warmStart) seed(c []int, max int, perturb bool, flip func(float64) bool) []int {
+	result := append([]int{}, c...)
+	for v, k := range ws.clusters {
+		if k == -1 || (perturb && flip(perturbation)) {
+			continue
+		}
+		result[v] = k % (max + 1)
+	}
+	return result
+}
+
+// seedPopulation warm starts the first individuals of the population
+func (ws *warmStart) seedPopulation(p moea.Population, count, n, lbits int, rng moea.RNG) {
+	for i := 0; i < count && i < p.Len(); i++ {
+		switch ind := p.Individual(i).(type) {
+		case *integerIndividual:
+			ind.clusters = ws.seed(ind.clusters, ind.max, i > 0, rng.Flip)
+		default:
+			s := ws.seed(clustersOf(ind, n), 1<<uint(lbits)-1, i > 0, rng.Flip)
+			setClusters(ind, s, lbits)
+		}
+	}
+}
+
+// randomSeed returns the warm start for a repetition of an algorithm
+// starting from a single clustering, with random clusters, from 0 to
+// max, for unknown vertices and, except in the first repetition, for
+// random vertices
+func (ws *warmStart) randomSeed(rng *rand.Rand, max int, first bool) []int {
+	c := make([]int, len(ws.clusters))
+	for v := range c {
+		c[v] = rng.Intn(max + 1)
+	}
+	return ws.seed(c, max, !first, func(p float64) bool { return rng.Float64() < p })
+}
+
+// moved returns how many known vertices are not in the cluster matched
+// to their warm start cluster, matching clusters one to one, greedily by
+// the number of vertices they share, so that neither renumbering nor
+// merging nor splitting clusters go unnoticed
+func (ws *warmStart) moved(c []int) int {
+	index := map[[2]int]int{}
+	var overlaps []struct {
+		clusters [2]int
+		count    int
+	}
+	for v, k := range ws.clusters {
+		if k == -1 {
+			continue
+		}
+		key := [2]int{c[v], k}
+		i, ok := index[key]
+		if !ok {
+			i = len(overlaps)
+			index[key] = i
+			overlaps = append(overlaps, struct {
+				clusters [2]int
+				count    int
+			}{key, 0})
+		}
+		overlaps[i].count++
+	}
+	sort.SliceStable(overlaps, func(i, j int) bool { return overlaps[i].count > overlaps[j].count })
+	matched := [2]map[int]bool{{}, {}}
+	kept := 0
+	for _, o := range overlaps {
+		if matched[0][o.clusters[0]] || matched[1][o.clusters[1]] {
+			continue
+		}
+		matched[0][o.clusters[0]] = true
+		matched[1][o.clusters[1]] = true
+		kept += o.count
+	}
+	return ws.known - kept
+}
+
+// stability returns the penalty added to the negative Turbo MQ for the
+// vertices moved from the warm start, as a fraction of weight
+func (ws *warmStart) stability(c []int, weight float64) float64 {
+	if ws.known == 0 {
+		return 0
+	}
+	return weight * float64(ws.moved(c)) / float64(ws.known)
+}
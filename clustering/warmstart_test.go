@@ -0,0 +1,87 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/project-draco/moea"
+	"github.com/project-draco/moea/binary"
+)
+
+const layout = `p_A.java/[CN]/A/[MT]/a() p_A.java/[CN]/A/[MT]/b()
+p_A.java/[CN]/A/[MT]/b() p_B.java/[CN]/B/[MT]/c()
+q_C.java/[CN]/C/[MT]/d() p_B.java/[CN]/B/[MT]/c()
+x y
+`
+
+func TestWarmStart(t *testing.T) {
+	g, err := readGraph(strings.NewReader(layout))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		layout string
+		want   []int
+	}{
+		{"package", []int{0, 0, 0, 1, -1, -1}},
+		{"file", []int{0, 0, 1, 2, -1, -1}},
+		{"class", []int{0, 0, 1, 2, -1, -1}},
+	} {
+		ws, err := newLayoutWarmStart(g, test.layout)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(ws.clusters, test.want) || ws.known != 4 {
+			t.Errorf("%v: expected %v, got %v", test.layout, test.want, ws.clusters)
+		}
+	}
+	ws, _ := newLayoutWarmStart(g, "file")
+	for _, test := range []struct {
+		clusters []int
+		moved    int
+	}{
+		{[]int{5, 5, 3, 4, 0, 0}, 0},
+		{[]int{1, 1, 1, 1, 0, 0}, 2},
+		{[]int{1, 2, 3, 4, 0, 0}, 1},
+		{[]int{1, 1, 3, 3, 0, 0}, 1},
+	} {
+		if moved := ws.moved(test.clusters); moved != test.moved {
+			t.Errorf("%v: expected %v moved, got %v", test.clusters, test.moved, moved)
+		}
+	}
+	dot := "digraph {\nsubgraph cluster0 {\n\"x\";\n\"y\";\n}\nsubgraph cluster1 {\n\"p_A.java/[CN]/A/[MT]/a()\";\n}\n}\n"
+	ws, err = readWarmStart("previous.dot", strings.NewReader(dot), g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{0, -1, -1, -1, 1, 1}; !reflect.DeepEqual(ws.clusters, want) {
+		t.Errorf("expected %v, got %v", want, ws.clusters)
+	}
+}
+
+func TestSeedPopulation(t *testing.T) {
+	ws := newWarmStart("test", []string{"a", "b", "", "c", "a", "d", "e"})
+	want := []int{0, 1, -1, 2, 0, 3, 4}
+	n, lbits := len(want), 3
+	lengths := []int{lbits, lbits, lbits, lbits, lbits, lbits, lbits}
+	rng := moea.NewXorshiftWithSeed(1)
+	for _, p := range []moea.Population{
+		binary.NewRandomBinaryPopulation(4, lengths, nil, rng),
+		newRandomIntegerPopulation(4, n, 4, rng),
+	} {
+		ws.seedPopulation(p, 1, n, lbits, rng)
+		ind := p.Individual(0)
+		for v, k := range want {
+			var value int
+			if b, ok := ind.Value(v).(binary.BinaryString); ok {
+				value = int(b.Int().Int64())
+			} else {
+				value = ind.Value(v).(int)
+			}
+			if k != -1 && value != k {
+				t.Errorf("%T: expected cluster %v of vertex %v, got %v", p, k, v, value)
+			}
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+func main() {
+	workdir := flag.String("workdir", ".", "directory holding one directory per repository")
+	stateFile := flag.String("state", "", "state file (default <workdir>/state.json)")
+	concurrency := flag.Int("concurrency", 1, "number of repositories processed at the same time")
+	retries := flag.Int("retries", 2, "number of retries of a failed repository")
+	retryDelay := flag.Duration("retry-delay", 30*time.Second,
+		"delay before the first retry, multiplied by the attempt number afterwards")
+	update := flag.Bool("update", false, "process again repositories already done, fetching new commits")
+	g2h := flag.String("g2h", "g2h", "g2h command")
+	coChange := flag.String("co-change", "co-change", "co-change command")
+	miningArgs := flag.String("mining-args", "", "arguments of the co-change command")
+	github := flag.String("github", "https://github.com",
+		"URL prefix of the repositories found in GitHub search results")
+	flag.Parse()
+	if flag.NArg() < 1 || *concurrency < 1 {
+		fmt.Fprintf(os.Stderr, "usage: %v [options] <manifest>\n", path.Base(os.Args[0]))
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+	repos, err := readManifest(flag.Arg(0), *github)
+	check(err, "could not read manifest")
+	// commands run inside the repositories' directories, so paths must be absolute
+	*workdir, err = filepath.Abs(*workdir)
+	check(err, "invalid workdir")
+	check(os.MkdirAll(*workdir, 0755), "could not create workdir")
+	*g2h, err = command(*g2h)
+	check(err, "could not find g2h")
+	*coChange, err = command(*coChange)
+	check(err, "could not find co-change")
+	if *stateFile == "" {
+		*stateFile = filepath.Join(*workdir, "state.json")
+	}
+	s, err := readState(*stateFile)
+	check(err, "could not read state")
+	r := &runner{
+		workdir:    *workdir,
+		g2h:        *g2h,
+		coChange:   *coChange,
+		miningArgs: strings.Fields(*miningArgs),
+		retries:    *retries,
+		retryDelay: *retryDelay,
+		state:      s,
+	}
+
+	ch := make(chan repository)
+	var wg sync.WaitGroup
+	for i := 0; i < *concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for repo := range ch {
+				log.Printf("%v: started", repo.Name)
+				check(r.run(repo), "could not process "+repo.Name)
+				p := s.get(repo.Name)
+				log.Printf("%v: %v (%v source commits, %v converted) %v",
+					repo.Name, p.Status, p.SourceCommits, p.ConvertedCommits, p.Error)
+			}
+		}()
+	}
+	for _, repo := range pending(repos, s, *update) {
+		ch <- repo
+	}
+	close(ch)
+	wg.Wait()
+
+	failed := 0
+	for _, repo := range repos {
+		if s.get(repo.Name).Status != statusDone {
+			failed++
+		}
+	}
+	log.Printf("%v repositories done, %v failed", len(repos)-failed, failed)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// pending returns the repositories to be processed, which are all of them
+// on updates, and otherwise those not done yet
+func pending(repos []repository, s *state, update bool) []repository {
+	var result []repository
+	for _, repo := range repos {
+		if !update && s.get(repo.Name).Status == statusDone {
+			log.Printf("%v: already done", repo.Name)
+			continue
+		}
+		result = append(result, repo)
+	}
+	return result
+}
+
+// command returns the absolute path of a command given by name or path
+func command(name string) (string, error) {
+	p, err := exec.LookPath(name)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Abs(p)
+}
+
+func check(err error, info string) {
+	if err != nil {
+		log.Fatalf("%v: %v", info, err)
+	}
+}
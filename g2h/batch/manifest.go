@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// repository is an entry of the manifest. Source is a git URL or the
+// path of a local repository
+type repository struct {
+	Name   string `json:"name"`
+	Source string `json:"source"`
+}
+
+// searchResult is a page of the GitHub GraphQL search in query.graphql,
+// as saved in repositories.json
+type searchResult struct {
+	Data struct {
+		Search struct {
+			Edges []struct {
+				Node struct {
+					Owner struct {
+						Login string `json:"login"`
+					} `json:"owner"`
+					Name string `json:"name"`
+				} `json:"node"`
+			} `json:"edges"`
+		} `json:"search"`
+	} `json:"data"`
+}
+
+var unsafeNameRegexp = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// readManifest reads a JSON array whose elements are either repositories
+// or pages of GitHub search results, whose repositories are cloned from
+// githubURL
+func readManifest(filename, githubURL string) ([]repository, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var elements []json.RawMessage
+	if err := json.Unmarshal(b, &elements); err != nil {
+		return nil, err
+	}
+	var result []repository
+	names := map[string]bool{}
+	add := func(r repository) error {
+		if r.Source == "" {
+			return fmt.Errorf("repository %q without source", r.Name)
+		}
+		if r.Name == "" {
+			r.Name = defaultName(r.Source)
+		}
+		if r.Name == "." || r.Name == ".." || unsafeNameRegexp.MatchString(r.Name) {
+			return fmt.Errorf("invalid repository name %q", r.Name)
+		}
+		if names[r.Name] {
+			return fmt.Errorf("duplicated repository %q", r.Name)
+		}
+		names[r.Name] = true
+		result = append(result, r)
+		return nil
+	}
+	for _, element := range elements {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(element, &fields); err != nil {
+			return nil, err
+		}
+		if _, ok := fields["data"]; !ok {
+			var r repository
+			if err := json.Unmarshal(element, &r); err != nil {
+				return nil, err
+			}
+			if err := add(r); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		var page searchResult
+		if err := json.Unmarshal(element, &page); err != nil {
+			return nil, err
+		}
+		for _, edge := range page.Data.Search.Edges {
+			node := edge.Node
+			name := node.Name
+			// distinct owners may have repositories with the same name
+			if names[name] {
+				name = node.Owner.Login + "_" + name
+			}
+			err := add(repository{
+				Name:   name,
+				Source: strings.TrimSuffix(githubURL, "/") + "/" + node.Owner.Login + "/" + node.Name + ".git",
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return result, nil
+}
+
+// defaultName derives a directory name from the last element of a
+// repository URL or path
+func defaultName(source string) string {
+	s := strings.TrimRight(source, "/"+string(os.PathSeparator))
+	if i := strings.LastIndexAny(s, "/:"+string(os.PathSeparator)); i != -1 {
+		s = s[i+1:]
+	}
+	return unsafeNameRegexp.ReplaceAllString(strings.TrimSuffix(s, ".git"), "_")
+}
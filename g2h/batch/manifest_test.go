@@ -0,0 +1,54 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestReadManifest(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		want     []repository
+		wantErr  bool
+	}{
+		{
+			"repositories and search results",
+			`[
+				{"source": "/tmp/a-repo/"},
+				{"name": "b", "source": "git@host:x/b.git"},
+				{"data": {"search": {"edges": [{"node": {"owner": {"login": "o"}, "name": "c"}}, {"node": {"owner": {"login": "p"}, "name": "c"}}]}}}
+			]`,
+			[]repository{
+				{"a-repo", "/tmp/a-repo/"},
+				{"b", "git@host:x/b.git"},
+				{"c", "https://github.com/o/c.git"},
+				{"p_c", "https://github.com/p/c.git"},
+			},
+			false,
+		},
+		{"duplicated names", `[{"source": "x/a"}, {"source": "y/a.git"}]`, nil, true},
+		{"missing source", `[{"name": "a"}]`, nil, true},
+		{"invalid name", `[{"name": "../a", "source": "a"}]`, nil, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f, err := ioutil.TempFile("", "manifest")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.Remove(f.Name())
+			f.WriteString(test.manifest)
+			f.Close()
+			got, err := readManifest(f.Name(), "https://github.com/")
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Unexpected error %v", err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Got %v want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// runner converts and mines repositories, each one in its own directory
+// of workdir holding the source mirror, the Historage repository and the
+// mining outputs
+type runner struct {
+	workdir    string
+	g2h        string
+	coChange   string
+	miningArgs []string
+	retries    int
+	retryDelay time.Duration
+	state      *state
+}
+
+// run processes a repository, retrying failed attempts
+func (r *runner) run(repo repository) error {
+	dir := filepath.Join(r.workdir, repo.Name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	var err error
+	for attempt := 0; attempt <= r.retries; attempt++ {
+		if attempt > 0 {
+			log.Printf("%v: attempt %v failed: %v", repo.Name, attempt, err)
+			time.Sleep(r.retryDelay * time.Duration(attempt))
+		}
+		if err = r.attempt(repo, dir); err == nil {
+			return nil
+		}
+	}
+	return r.state.update(repo.Name, func(p *progress) {
+		p.Status = statusFailed
+		p.Error = err.Error()
+		p.Finished = time.Now()
+	})
+}
+
+// attempt runs every step of a repository. Each step may be interrupted
+// and run again: fetching and converting are incremental, and mining
+// starts from the last mined Historage commit
+func (r *runner) attempt(repo repository, dir string) error {
+	if err := r.state.update(repo.Name, func(p *progress) {
+		p.Source = repo.Source
+		p.Status = statusFetching
+		p.Attempts++
+		p.Error = ""
+		p.Started = time.Now()
+		p.Finished = time.Time{}
+	}); err != nil {
+		return err
+	}
+	start := time.Now()
+	source, err := r.fetch(repo, dir)
+	if err != nil {
+		return fmt.Errorf("could not fetch %v: %v", repo.Source, err)
+	}
+	sourceCommits, err := countCommits(source)
+	if err != nil {
+		return err
+	}
+	if err := r.state.update(repo.Name, func(p *progress) {
+		p.Status = statusConverting
+		p.SourceCommits = sourceCommits
+		p.FetchSeconds = time.Since(start).Seconds()
+	}); err != nil {
+		return err
+	}
+
+	start = time.Now()
+	hr := filepath.Join(dir, "historage.git")
+	before, err := countCommits(hr)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := runCommand(dir, ioutil.Discard, r.g2h, source, hr); err != nil {
+		return fmt.Errorf("could not convert: %v", err)
+	}
+	after, err := countCommits(hr)
+	if err != nil {
+		return err
+	}
+	if err := r.state.update(repo.Name, func(p *progress) {
+		p.Status = statusMining
+		p.HistorageCommits = after
+		p.ConvertedCommits = after - before
+		p.ConvertSeconds = time.Since(start).Seconds()
+	}); err != nil {
+		return err
+	}
+
+	start = time.Now()
+	output, head, err := r.mine(r.state.get(repo.Name).MinedHead, dir, hr)
+	if err != nil {
+		return fmt.Errorf("could not mine: %v", err)
+	}
+	return r.state.update(repo.Name, func(p *progress) {
+		p.Status = statusDone
+		if output != "" {
+			p.MinedHead = head
+			p.Outputs = append(p.Outputs, output)
+			p.MiningSeconds = time.Since(start).Seconds()
+		}
+		p.Finished = time.Now()
+	})
+}
+
+// fetch returns the local repository to be converted, which is either the
+// source itself or a mirror of it, cloned or updated
+func (r *runner) fetch(repo repository, dir string) (string, error) {
+	if fi, err := os.Stat(repo.Source); err == nil && fi.IsDir() {
+		return filepath.Abs(repo.Source)
+	}
+	mirror := filepath.Join(dir, "source.git")
+	if _, err := os.Stat(mirror); err == nil {
+		return mirror, runCommand(mirror, ioutil.Discard, "git", "remote", "update", "--prune")
+	}
+	// cloning into a temporary directory avoids resuming from a partial clone
+	tmp := mirror + ".tmp"
+	if err := os.RemoveAll(tmp); err != nil {
+		return "", err
+	}
+	if err := runCommand(dir, ioutil.Discard, "git", "clone", "--quiet", "--mirror", repo.Source, tmp); err != nil {
+		return "", err
+	}
+	return mirror, os.Rename(tmp, mirror)
+}
+
+// mine mines the Historage commits after minedHead, returning the name
+// of the output file, if any, and the mined Historage commit
+func (r *runner) mine(minedHead, dir, hr string) (string, string, error) {
+	out, err := exec.Command("git", "-C", hr, "rev-parse", "--verify", "--quiet", "HEAD").Output()
+	if err != nil {
+		// an empty Historage repository has nothing to be mined
+		return "", "", nil
+	}
+	head := strings.TrimSpace(string(out))
+	if head == minedHead {
+		return "", head, nil
+	}
+	args := append([]string{}, r.miningArgs...)
+	output := "co-change.txt"
+	if minedHead != "" {
+		args = append(args, "-range="+minedHead+".."+head)
+		output = fmt.Sprintf("co-change-%v-%v.txt", minedHead[:7], head[:7])
+	}
+	f, err := os.Create(filepath.Join(dir, output+".tmp"))
+	if err != nil {
+		return "", "", err
+	}
+	err = runCommand(hr, f, r.coChange, args...)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return "", "", err
+	}
+	return output, head, os.Rename(f.Name(), filepath.Join(dir, output))
+}
+
+// countCommits returns the number of commits reachable from the branches
+// of a repository
+func countCommits(repo string) (int, error) {
+	if _, err := os.Stat(repo); err != nil {
+		return 0, err
+	}
+	out, err := exec.Command("git", "-C", repo, "rev-list", "--count", "--branches").Output()
+	if err != nil {
+		return 0, fmt.Errorf("could not count commits of %v: %v", repo, err)
+	}
+	return strconv.Atoi(strings.TrimSpace(string(out)))
+}
+
+// runCommand runs a command in dir, including the end of its standard
+// error in the returned error
+func runCommand(dir string, stdout io.Writer, name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	cmd.Dir = dir
+	cmd.Stdout = stdout
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if len(msg) > 1000 {
+			msg = "..." + msg[len(msg)-1000:]
+		}
+		return fmt.Errorf("%v %v: %v: %v", name, strings.Join(args, " "), err, msg)
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// stubs of g2h and co-change, which log their arguments. g2h copies the
+// branches of the source, failing while the failures file has a positive
+// number, and co-change writes a rule
+const (
+	g2hStub = `#!/bin/sh
+echo "$@" >> "$0.log"
+n=$(cat "$0.failures" 2>/dev/null || echo 0)
+if [ "$n" -gt 0 ]; then
+	echo $((n - 1)) > "$0.failures"
+	echo "conversion failed" >&2
+	exit 1
+fi
+[ -d "$2" ] || git init --quiet --bare "$2" || exit 1
+git -C "$2" fetch --quiet "$1" "+refs/heads/*:refs/heads/*"
+`
+	coChangeStub = `#!/bin/sh
+echo "$@" >> "$0.log"
+printf 'a\tb\t1\n'
+`
+)
+
+func TestRunner(t *testing.T) {
+	dir, err := ioutil.TempDir("", "batch")
+	checkT(t, err)
+	defer os.RemoveAll(dir)
+	source := filepath.Join(dir, "source")
+	git(t, ".", "init", "--quiet", source)
+	commit(t, source, "first")
+	commit(t, source, "second")
+	g2h := writeStub(t, dir, "g2h", g2hStub)
+	coChange := writeStub(t, dir, "co-change", coChangeStub)
+	workdir := filepath.Join(dir, "work")
+	checkT(t, os.MkdirAll(workdir, 0755))
+	stateFile := filepath.Join(workdir, "state.json")
+	newRunner := func(retries int) *runner {
+		s, err := readState(stateFile)
+		checkT(t, err)
+		return &runner{
+			workdir:    workdir,
+			g2h:        g2h,
+			coChange:   coChange,
+			miningArgs: []string{"-header"},
+			retries:    retries,
+			state:      s,
+		}
+	}
+	repo := repository{"r", source}
+	hr := filepath.Join(workdir, "r", "historage.git")
+
+	// the conversion fails in both attempts
+	checkT(t, ioutil.WriteFile(g2h+".failures", []byte("2"), 0644))
+	checkT(t, newRunner(1).run(repo))
+	p := newRunner(0).state.get("r")
+	if p.Status != statusFailed || p.Attempts != 2 || !strings.Contains(p.Error, "conversion failed") {
+		t.Fatalf("expected 2 failed attempts, got %+v", p)
+	}
+	if n := len(lines(t, g2h+".log")); n != 2 {
+		t.Errorf("expected 2 conversions, got %v", n)
+	}
+
+	// a failed repository is pending, and resumed from the saved state
+	r := newRunner(1)
+	if got := pending([]repository{repo}, r.state, false); len(got) != 1 {
+		t.Fatalf("expected the failed repository to be pending, got %v", got)
+	}
+	checkT(t, r.run(repo))
+	head := git(t, hr, "rev-parse", "HEAD")
+	p = newRunner(0).state.get("r")
+	want := progress{
+		Source:           source,
+		Status:           statusDone,
+		Attempts:         3,
+		SourceCommits:    2,
+		HistorageCommits: 2,
+		ConvertedCommits: 2,
+		MinedHead:        head,
+		Outputs:          []string{"co-change.txt"},
+	}
+	if !equalProgress(p, want) {
+		t.Fatalf("expected %+v, got %+v", want, p)
+	}
+	if got := lines(t, coChange+".log"); !reflect.DeepEqual(got, []string{"-header"}) {
+		t.Errorf("expected the whole history mined, got %v", got)
+	}
+	if _, err := os.Stat(filepath.Join(workdir, "r", "co-change.txt")); err != nil {
+		t.Error(err)
+	}
+
+	// done repositories are skipped, unless updating
+	r = newRunner(0)
+	if got := pending([]repository{repo}, r.state, false); len(got) != 0 {
+		t.Errorf("expected no pending repository, got %v", got)
+	}
+	if got := pending([]repository{repo}, r.state, true); len(got) != 1 {
+		t.Errorf("expected the repository to be updated, got %v", got)
+	}
+
+	// a run interrupted while mining, leaving a partial output, is resumed
+	// without mining again what was already mined
+	commit(t, source, "third")
+	checkT(t, r.state.update("r", func(p *progress) { p.Status = statusMining }))
+	checkT(t, ioutil.WriteFile(filepath.Join(workdir, "r", "co-change.txt.tmp"), []byte("partial"), 0644))
+	r = newRunner(0)
+	checkT(t, r.run(repo))
+	newHead := git(t, hr, "rev-parse", "HEAD")
+	output := "co-change-" + head[:7] + "-" + newHead[:7] + ".txt"
+	p = r.state.get("r")
+	want.Attempts, want.SourceCommits, want.HistorageCommits, want.ConvertedCommits = 4, 3, 3, 1
+	want.MinedHead, want.Outputs = newHead, []string{"co-change.txt", output}
+	if !equalProgress(p, want) {
+		t.Fatalf("expected %+v, got %+v", want, p)
+	}
+	if got := lines(t, coChange+".log"); !reflect.DeepEqual(got[1:], []string{"-header -range=" + head + ".." + newHead}) {
+		t.Errorf("expected only the new commits mined, got %v", got)
+	}
+	b, err := ioutil.ReadFile(filepath.Join(workdir, "r", output))
+	checkT(t, err)
+	if string(b) != "a\tb\t1\n" {
+		t.Errorf("unexpected output %q", b)
+	}
+
+	// nothing new is not mined again
+	checkT(t, newRunner(0).run(repo))
+	if n := len(lines(t, coChange+".log")); n != 2 {
+		t.Errorf("expected 2 minings, got %v", n)
+	}
+	if p := newRunner(0).state.get("r"); p.Status != statusDone || len(p.Outputs) != 2 {
+		t.Errorf("unexpected progress %+v", p)
+	}
+}
+
+// equalProgress compares the progress but its timings
+func equalProgress(p, q progress) bool {
+	p.FetchSeconds, p.ConvertSeconds, p.MiningSeconds = 0, 0, 0
+	p.Started, p.Finished = q.Started, q.Finished
+	return reflect.DeepEqual(p, q)
+}
+
+func writeStub(t *testing.T, dir, name, script string) string {
+	name = filepath.Join(dir, name)
+	checkT(t, ioutil.WriteFile(name, []byte(script), 0755))
+	return name
+}
+
+func commit(t *testing.T, repo, message string) {
+	checkT(t, ioutil.WriteFile(filepath.Join(repo, message), []byte(message), 0644))
+	git(t, repo, "add", message)
+	git(t, repo, "-c", "user.name=A", "-c", "user.email=a@b", "commit", "--quiet", "-m", message)
+}
+
+func git(t *testing.T, dir string, args ...string) string {
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v: %s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func lines(t *testing.T, name string) []string {
+	b, err := ioutil.ReadFile(name)
+	checkT(t, err)
+	return strings.Split(strings.TrimSpace(string(b)), "\n")
+}
+
+func checkT(t *testing.T, err error) {
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// statuses of a repository, in the order they are reached
+const (
+	statusPending    = "pending"
+	statusFetching   = "fetching"
+	statusConverting = "converting"
+	statusMining     = "mining"
+	statusDone       = "done"
+	statusFailed     = "failed"
+)
+
+// progress is the state of a repository
+type progress struct {
+	Source           string  `json:"source"`
+	Status           string  `json:"status"`
+	Attempts         int     `json:"attempts"`
+	Error            string  `json:"error,omitempty"`
+	SourceCommits    int     `json:"sourceCommits"`
+	HistorageCommits int     `json:"historageCommits"`
+	ConvertedCommits int     `json:"convertedCommits"`
+	FetchSeconds     float64 `json:"fetchSeconds"`
+	ConvertSeconds   float64 `json:"convertSeconds"`
+	MiningSeconds    float64 `json:"miningSeconds"`
+	// MinedHead is the Historage commit up to which the repository was mined
+	MinedHead string    `json:"minedHead,omitempty"`
+	Outputs   []string  `json:"outputs,omitempty"`
+	Started   time.Time `json:"started"`
+	Finished  time.Time `json:"finished"`
+}
+
+// state is the progress of every repository, saved to a file after each
+// change so that an interrupted batch can be resumed
+type state struct {
+	filename     string
+	mu           sync.Mutex
+	Repositories map[string]*progress `json:"repositories"`
+}
+
+func readState(filename string) (*state, error) {
+	s := &state{filename: filename, Repositories: map[string]*progress{}}
+	b, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, s); err != nil {
+		return nil, err
+	}
+	if s.Repositories == nil {
+		s.Repositories = map[string]*progress{}
+	}
+	return s, nil
+}
+
+// get returns a copy of the progress of a repository
+func (s *state) get(name string) progress {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if p, ok := s.Repositories[name]; ok {
+		return *p
+	}
+	return progress{Status: statusPending}
+}
+
+// update changes the progress of a repository and saves the state
+func (s *state) update(name string, f func(p *progress)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.Repositories[name]
+	if !ok {
+		p = &progress{Status: statusPending}
+		s.Repositories[name] = p
+	}
+	f(p)
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	// the file is replaced atomically, so that a crash never leaves it truncated
+	tmp, err := ioutil.TempFile(filepath.Dir(s.filename), filepath.Base(s.filename))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(b, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.filename)
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+type commit struct {
+	hash      string
+	parents   []string
+	author    string
+	committer string
+	message   []byte
+}
+
+type change struct {
+	status byte
+	hash   string
+	path   string
+}
+
+// objectReader reads objects from a repository using a single
+// long-running git cat-file process
+type objectReader struct {
+	cmd *exec.Cmd
+	in  io.WriteCloser
+	out *bufio.Reader
+}
+
+func runGit(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %v: %v: %s",
+			strings.Join(args, " "), err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	return out, nil
+}
+
+func newObjectReader(dir string) (*objectReader, error) {
+	cmd := exec.Command("git", "-C", dir, "cat-file", "--batch")
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return &objectReader{cmd, in, bufio.NewReader(out)}, nil
+}
+
+func (r *objectReader) read(name string) ([]byte, error) {
+	if _, err := fmt.Fprintln(r.in, name); err != nil {
+		return nil, err
+	}
+	header, err := r.out.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	fields := strings.Fields(header)
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("could not read object %v: %v", name, strings.TrimSpace(header))
+	}
+	size, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, size+1)
+	if _, err := io.ReadFull(r.out, buf); err != nil {
+		return nil, err
+	}
+	return buf[:size], nil
+}
+
+func (r *objectReader) close() error {
+	r.in.Close()
+	io.Copy(ioutil.Discard, r.out)
+	return r.cmd.Wait()
+}
+
+func (r *objectReader) commit(hash string) (*commit, error) {
+	raw, err := r.read(hash)
+	if err != nil {
+		return nil, err
+	}
+	return parseCommit(hash, raw), nil
+}
+
+func parseCommit(hash string, raw []byte) *commit {
+	c := &commit{hash: hash}
+	idx := bytes.Index(raw, []byte("\n\n"))
+	headers := raw
+	if idx != -1 {
+		headers = raw[:idx]
+		c.message = raw[idx+2:]
+	}
+	for _, line := range strings.Split(string(headers), "\n") {
+		switch {
+		case strings.HasPrefix(line, "parent "):
+			c.parents = append(c.parents, line[len("parent "):])
+		case strings.HasPrefix(line, "author "):
+			c.author = line[len("author "):]
+		case strings.HasPrefix(line, "committer "):
+			c.committer = line[len("committer "):]
+		}
+	}
+	return c
+}
+
+// ident normalizes an author or committer line into the form accepted
+// by git fast-import, tolerating missing e-mails and malformed dates
+func ident(s string) string {
+	when := "0 +0000"
+	fields := strings.Fields(s)
+	if len(fields) >= 2 {
+		_, err1 := strconv.ParseInt(fields[len(fields)-2], 10, 64)
+		_, err2 := strconv.Atoi(fields[len(fields)-1])
+		if err1 == nil && err2 == nil {
+			when = fields[len(fields)-2] + " " + fields[len(fields)-1]
+			s = strings.Join(fields[:len(fields)-2], " ")
+		}
+	}
+	name, email := s, ""
+	if lt := strings.Index(s, "<"); lt != -1 {
+		name = s[:lt]
+		email = s[lt+1:]
+		if gt := strings.LastIndex(email, ">"); gt != -1 {
+			email = email[:gt]
+		}
+	}
+	clean := strings.NewReplacer("<", "", ">", "", "\n", " ")
+	name = strings.TrimSpace(clean.Replace(name))
+	email = strings.TrimSpace(clean.Replace(email))
+	if name == "" {
+		return fmt.Sprintf("<%v> %v", email, when)
+	}
+	return fmt.Sprintf("%v <%v> %v", name, email, when)
+}
+
+// diffTree returns the changes introduced by a commit with respect
+// to its first parent, or to the empty tree if it has no parents
+func diffTree(dir string, c *commit) ([]change, error) {
+	args := []string{"diff-tree", "-r", "-z", "--no-commit-id", "--raw"}
+	if len(c.parents) == 0 {
+		args = append(args, "--root", c.hash)
+	} else {
+		args = append(args, c.parents[0], c.hash)
+	}
+	out, err := runGit(dir, args...)
+	if err != nil {
+		return nil, err
+	}
+	var result []change
+	fields := strings.Split(string(out), "\x00")
+	for i := 0; i+1 < len(fields); i += 2 {
+		meta := strings.Fields(fields[i])
+		if len(meta) < 5 {
+			return nil, fmt.Errorf("unexpected diff-tree output: %v", fields[i])
+		}
+		if meta[0] == ":160000" || meta[1] == "160000" {
+			continue
+		}
+		result = append(result, change{meta[4][0], meta[3], fields[i+1]})
+	}
+	return result, nil
+}
+
+// quotePath quotes a path for git fast-import when necessary
+func quotePath(path string) string {
+	if !strings.ContainsAny(path, "\"\\\n") {
+		return path
+	}
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(path); i++ {
+		switch c := path[i]; {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < ' ':
+			fmt.Fprintf(&b, "\\%03o", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,450 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	importRef   = "refs/historage/import"
+	previousRef = "refs/historage/previous"
+	notesRef    = "refs/notes/historage"
+)
+
+type entry struct {
+	path    string
+	content string
+}
+
+// converter converts the commits of a source repository into a
+// Historage repository, where each Java file becomes a directory
+// holding one file per field, method and constructor
+type converter struct {
+	source, hr string
+	// mapping maps source commits to Historage commits
+	mapping map[string]string
+	log     io.Writer
+}
+
+func newConverter(source, hr string) (*converter, error) {
+	if _, err := runGit(source, "rev-parse", "--git-dir"); err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(hr); os.IsNotExist(err) {
+		if _, err := runGit(".", "init", "--bare", "--quiet", hr); err != nil {
+			return nil, err
+		}
+	}
+	c := &converter{
+		source:  source,
+		hr:      hr,
+		mapping: map[string]string{},
+		log:     os.Stderr,
+	}
+	return c, c.readMapping()
+}
+
+// readMapping reads the notes that record, for each Historage commit,
+// the source commit it was converted from
+func (c *converter) readMapping() error {
+	out, err := runGit(c.hr, "notes", "--ref="+notesRef, "list")
+	if err != nil {
+		return err
+	}
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	if len(lines) == 0 || lines[0] == "" {
+		return nil
+	}
+	objects, err := newObjectReader(c.hr)
+	if err != nil {
+		return err
+	}
+	defer objects.close()
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		note, err := objects.read(fields[0])
+		if err != nil {
+			return err
+		}
+		if nf := strings.Fields(string(note)); len(nf) > 0 {
+			c.mapping[nf[len(nf)-1]] = fields[1]
+		}
+	}
+	return nil
+}
+
+// references returns the source references matching pattern, mapped to
+// the commits they point to, along with those commits
+func (c *converter) references(pattern string) (map[string]string, []string, error) {
+	out, err := runGit(c.source, "for-each-ref", "--format=%(objectname) %(refname)", pattern)
+	if err != nil {
+		return nil, nil, err
+	}
+	refs := map[string]string{}
+	var tips []string
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 {
+			refs[fields[1]] = fields[0]
+			tips = append(tips, fields[0])
+		}
+	}
+	if len(tips) == 0 {
+		return nil, nil, fmt.Errorf("no references matching %v", pattern)
+	}
+	return refs, tips, nil
+}
+
+// convert converts every commit reachable from the source references
+// matching pattern that was not converted yet, returning how many commits
+// were converted
+func (c *converter) convert(pattern string) (int, error) {
+	refs, tips, err := c.references(pattern)
+	if err != nil {
+		return 0, err
+	}
+	out, err := runGit(c.source, append([]string{"rev-list", "--reverse", "--topo-order"}, tips...)...)
+	if err != nil {
+		return 0, err
+	}
+	commits := strings.Fields(string(out))
+	var matched map[string]string
+	if len(c.mapping) == 0 {
+		if matched, err = c.matchCommits(tips); err != nil {
+			return 0, err
+		}
+	}
+	var pending []string
+	for _, hash := range commits {
+		if _, ok := c.mapping[hash]; !ok {
+			pending = append(pending, hash)
+		}
+	}
+	if len(pending) > 0 || len(matched) > 0 {
+		if err := c.importCommits(pending, matched); err != nil {
+			return 0, err
+		}
+	}
+	return len(pending), c.updateRefs(refs, len(pending) > 0)
+}
+
+// matchCommits maps the commits of a Historage repository that has no
+// mapping notes, such as one converted by Kenja, to source commits with
+// the same author and committer dates and subject
+func (c *converter) matchCommits(tips []string) (map[string]string, error) {
+	if _, err := runGit(c.hr, "rev-parse", "-q", "--verify", "HEAD"); err != nil {
+		return nil, nil
+	}
+	format := "--format=%H %at %ct %s"
+	out, err := runGit(c.hr, "log", "--branches", "--topo-order", "--reverse", format)
+	if err != nil {
+		return nil, err
+	}
+	candidates := map[string][]string{}
+	for _, line := range strings.Split(string(out), "\n") {
+		if len(line) > 41 {
+			key := line[41:]
+			candidates[key] = append(candidates[key], line[:40])
+		}
+	}
+	out, err = runGit(c.source, append([]string{"log", "--topo-order", "--reverse", format}, tips...)...)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]string{}
+	for _, line := range strings.Split(string(out), "\n") {
+		if len(line) <= 41 {
+			continue
+		}
+		key := line[41:]
+		if hh := candidates[key]; len(hh) > 0 {
+			result[line[:40]] = hh[0]
+			c.mapping[line[:40]] = hh[0]
+			candidates[key] = hh[1:]
+		}
+	}
+	fmt.Fprintf(c.log, "%v commits matched in the Historage repository\n", len(result))
+	return result, nil
+}
+
+// importCommits converts commits and records the mapping notes of both
+// the converted and the matched commits
+func (c *converter) importCommits(commits []string, matched map[string]string) error {
+	marks, err := ioutil.TempFile("", "g2h-marks")
+	if err != nil {
+		return err
+	}
+	marks.Close()
+	defer os.Remove(marks.Name())
+	cmd := exec.Command("git", "-C", c.hr, "fast-import", "--quiet", "--force",
+		"--export-marks="+marks.Name())
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	objects, err := newObjectReader(c.source)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(stdin)
+	marked := map[string]int{}
+	for i, hash := range commits {
+		if err := c.writeCommit(w, objects, hash, i+1, marked); err != nil {
+			objects.close()
+			stdin.Close()
+			cmd.Wait()
+			return fmt.Errorf("could not convert commit %v: %v", hash, err)
+		}
+		marked[hash] = i + 1
+		if (i+1)%100 == 0 {
+			fmt.Fprintf(c.log, "%v/%v commits\n", i+1, len(commits))
+		}
+	}
+	objects.close()
+	if err := c.writeNotes(w, commits, matched); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	stdin.Close()
+	if err := cmd.Wait(); err != nil {
+		return err
+	}
+	if len(commits) > 0 {
+		if _, err := runGit(c.hr, "update-ref", "-d", importRef); err != nil {
+			return err
+		}
+	}
+	return c.readMarks(commits, marks.Name())
+}
+
+func (c *converter) writeNotes(w *bufio.Writer, commits []string, matched map[string]string) error {
+	msg := fmt.Sprintf("Map %v converted and %v matched commits", len(commits), len(matched))
+	fmt.Fprintf(w, "commit %v\ncommitter g2h <> %v +0000\n", notesRef, time.Now().Unix())
+	fmt.Fprintf(w, "data %v\n%v\n", len(msg), msg)
+	if _, err := runGit(c.hr, "rev-parse", "-q", "--verify", notesRef); err == nil {
+		fmt.Fprintf(w, "from %v^0\n", notesRef)
+	}
+	note := func(source, target string) {
+		content := "source " + source + "\n"
+		fmt.Fprintf(w, "N inline %v\ndata %v\n%v", target, len(content), content)
+	}
+	for i, hash := range commits {
+		note(hash, fmt.Sprintf(":%v", i+1))
+	}
+	sources := make([]string, 0, len(matched))
+	for source := range matched {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+	for _, source := range sources {
+		note(source, matched[source])
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
+
+func (c *converter) writeCommit(
+	w *bufio.Writer,
+	objects *objectReader,
+	hash string,
+	mark int,
+	marked map[string]int,
+) error {
+	cm, err := objects.commit(hash)
+	if err != nil {
+		return err
+	}
+	changes, err := diffTree(c.source, cm)
+	if err != nil {
+		return err
+	}
+	if len(cm.parents) == 0 {
+		fmt.Fprintf(w, "reset %v\n", importRef)
+	}
+	fmt.Fprintf(w, "commit %v\nmark :%v\n", importRef, mark)
+	fmt.Fprintf(w, "author %v\n", ident(cm.author))
+	fmt.Fprintf(w, "committer %v\n", ident(cm.committer))
+	fmt.Fprintf(w, "data %v\n%s\n", len(cm.message), cm.message)
+	for i, p := range cm.parents {
+		ref := c.mapping[p]
+		if m, ok := marked[p]; ok {
+			ref = fmt.Sprintf(":%v", m)
+		}
+		if ref == "" {
+			return fmt.Errorf("parent %v was not converted", p)
+		}
+		if i == 0 {
+			fmt.Fprintf(w, "from %v\n", ref)
+		} else {
+			fmt.Fprintf(w, "merge %v\n", ref)
+		}
+	}
+	for _, ch := range changes {
+		if !strings.HasSuffix(ch.path, ".java") {
+			continue
+		}
+		dir := historagePath(ch.path)
+		fmt.Fprintf(w, "D %v\n", quotePath(dir))
+		if ch.status == 'D' {
+			continue
+		}
+		src, err := objects.read(ch.hash)
+		if err != nil {
+			return err
+		}
+		jf, err := parseJava(src)
+		if err != nil {
+			fmt.Fprintf(c.log, "[Warning] %v: %v: %v\n", hash, ch.path, err)
+			continue
+		}
+		for _, e := range jf.entries(dir) {
+			fmt.Fprintf(w, "M 100644 inline %v\ndata %v\n%v\n", quotePath(e.path), len(e.content), e.content)
+		}
+	}
+	fmt.Fprintln(w)
+	return nil
+}
+
+func (c *converter) readMarks(commits []string, marksFile string) error {
+	buf, err := ioutil.ReadFile(marksFile)
+	if err != nil {
+		return err
+	}
+	byMark := map[string]string{}
+	for _, line := range strings.Split(string(buf), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 {
+			byMark[fields[0]] = fields[1]
+		}
+	}
+	for i, hash := range commits {
+		hr, ok := byMark[fmt.Sprintf(":%v", i+1)]
+		if !ok {
+			return fmt.Errorf("missing mark for commit %v", hash)
+		}
+		c.mapping[hash] = hr
+	}
+	return nil
+}
+
+// updateRefs points each Historage branch to the conversion of the
+// corresponding source branch and mirrors the source HEAD. When new
+// commits were converted, the previous Historage HEAD is kept at
+// previousRef, so that the new segment can be mined on its own.
+func (c *converter) updateRefs(refs map[string]string, converted bool) error {
+	if converted {
+		if out, err := runGit(c.hr, "rev-parse", "-q", "--verify", "HEAD"); err == nil {
+			if _, err := runGit(c.hr, "update-ref", previousRef, strings.TrimSpace(string(out))); err != nil {
+				return err
+			}
+		} else if _, err := runGit(c.hr, "update-ref", "-d", previousRef); err != nil {
+			return err
+		}
+	}
+	names := make([]string, 0, len(refs))
+	for name := range refs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		target := c.mapping[refs[name]]
+		if target == "" {
+			continue
+		}
+		if !strings.HasPrefix(name, "refs/heads/") {
+			name = "refs/heads/" + name[strings.LastIndex(name, "/")+1:]
+		}
+		if _, err := runGit(c.hr, "update-ref", name, target); err != nil {
+			return err
+		}
+	}
+	if out, err := runGit(c.source, "symbolic-ref", "-q", "HEAD"); err == nil {
+		head := strings.TrimSpace(string(out))
+		if _, ok := refs[head]; ok {
+			_, err := runGit(c.hr, "symbolic-ref", "HEAD", head)
+			return err
+		}
+	}
+	return nil
+}
+
+// segment returns the range of Historage commits converted by the last
+// update, suitable for the -range option of the co-change miner
+func (c *converter) segment() (string, error) {
+	if _, err := runGit(c.hr, "rev-parse", "-q", "--verify", previousRef); err != nil {
+		return "", nil
+	}
+	out, err := runGit(c.hr, "rev-parse", previousRef, "HEAD")
+	if err != nil {
+		return "", err
+	}
+	hashes := strings.Fields(string(out))
+	return hashes[0] + ".." + hashes[1], nil
+}
+
+// historagePath returns the directory of a Java file within a Historage
+// repository, where directory separators are replaced by underscores
+func historagePath(path string) string {
+	return strings.Replace(path, "/", "_", -1)
+}
+
+// entries returns the Historage files of a Java file stored at dir
+func (f *javaFile) entries(dir string) []entry {
+	var result []entry
+	if f.pkg != "" {
+		result = append(result, entry{dir + "/package", f.pkg + "\n"})
+	}
+	for _, t := range f.types {
+		result = t.entries(dir+"/[CN]/"+t.name, result)
+	}
+	return result
+}
+
+func (t *javaType) entries(dir string, result []entry) []entry {
+	if len(t.extends) > 0 {
+		result = append(result, entry{dir + "/extend", strings.Join(t.extends, "\n") + "\n"})
+	}
+	if len(t.implements) > 0 {
+		result = append(result, entry{dir + "/implements", strings.Join(t.implements, "\n") + "\n"})
+	}
+	for _, fe := range t.fields {
+		result = append(result, entry{dir + "/[FE]/" + fe.name, fe.body + "\n"})
+	}
+	for _, kind := range []string{"[MT]", "[CS]"} {
+		members := t.methods
+		if kind == "[CS]" {
+			members = t.constructors
+		}
+		for _, m := range members {
+			mdir := fmt.Sprintf("%v/%v/%v(%v)", dir, kind, m.name, strings.Join(m.signature, ","))
+			if m.hasBody {
+				result = append(result, entry{mdir + "/body", m.body + "\n"})
+			}
+			parameters := strings.Join(m.parameters, "\n")
+			if parameters != "" {
+				parameters += "\n"
+			}
+			result = append(result, entry{mdir + "/parameters", parameters})
+		}
+	}
+	for _, nt := range t.types {
+		result = nt.entries(dir+"/[CN]/"+nt.name, result)
+	}
+	return result
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConvert(t *testing.T) {
+	dir, err := ioutil.TempDir("", "g2h")
+	checkT(t, err)
+	defer os.RemoveAll(dir)
+	source, hr := filepath.Join(dir, "source"), filepath.Join(dir, "hr")
+	git(t, ".", "init", "--quiet", source)
+	commitFile(t, source, "src/p/C.java", "package p; class C { int f; void m() { } }", "first")
+	commitFile(t, source, "src/p/C.java", "package p; class C { int f = 1; void m() { f++; } }", "second")
+
+	c, err := newConverter(source, hr)
+	checkT(t, err)
+	c.log = ioutil.Discard
+	n, err := c.convert("refs/heads")
+	checkT(t, err)
+	if n != 2 {
+		t.Errorf("Expected %v but was %v", 2, n)
+	}
+	got := git(t, hr, "diff-tree", "-r", "--name-only", "--no-commit-id", "HEAD")
+	want := "src_p_C.java/[CN]/C/[FE]/f\nsrc_p_C.java/[CN]/C/[MT]/m()/body"
+	if got != want {
+		t.Errorf("Got\n%v\nwant\n%v", got, want)
+	}
+
+	// a repository without mapping notes is matched by commit metadata
+	git(t, hr, "update-ref", "-d", notesRef)
+	commitFile(t, source, "src/p/D.java", "class D { }", "third")
+	c, err = newConverter(source, hr)
+	checkT(t, err)
+	c.log = ioutil.Discard
+	n, err = c.convert("refs/heads")
+	checkT(t, err)
+	if n != 1 {
+		t.Errorf("Expected %v but was %v", 1, n)
+	}
+	r, err := c.segment()
+	checkT(t, err)
+	got = git(t, hr, "log", "--format=%s", r)
+	if got != "third" {
+		t.Errorf("Expected %v but was %v", "third", got)
+	}
+
+	c, err = newConverter(source, hr)
+	checkT(t, err)
+	if len(c.mapping) != 3 {
+		t.Errorf("Expected %v but was %v", 3, len(c.mapping))
+	}
+}
+
+func commitFile(t *testing.T, repo, path, content, message string) {
+	checkT(t, os.MkdirAll(filepath.Dir(filepath.Join(repo, path)), 0755))
+	checkT(t, ioutil.WriteFile(filepath.Join(repo, path), []byte(content), 0644))
+	git(t, repo, "add", path)
+	git(t, repo, "-c", "user.name=A", "-c", "user.email=a@b", "commit", "--quiet", "-m", message)
+}
+
+func git(t *testing.T, dir string, args ...string) string {
+	out, err := runGit(dir, args...)
+	checkT(t, err)
+	return strings.TrimSpace(string(out))
+}
+
+func checkT(t *testing.T, err error) {
+	if err != nil {
+		t.Fatal(err)
+	}
+}
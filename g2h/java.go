@@ -0,0 +1,609 @@
+package main
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/project-draco/naming"
+)
+
+// javaFile is the structure of a Java compilation unit that is relevant
+// to a Historage repository
+type javaFile struct {
+	pkg   string
+	types []*javaType
+}
+
+type javaType struct {
+	name         string
+	extends      []string
+	implements   []string
+	fields       []javaMember
+	methods      []javaMember
+	constructors []javaMember
+	types        []*javaType
+}
+
+// javaMember is a field, method or constructor. Fields have nil parameters
+// and their declaration as body.
+type javaMember struct {
+	name       string
+	signature  []string
+	parameters []string
+	body       string
+	hasBody    bool
+}
+
+type tokenKind int
+
+const (
+	word tokenKind = iota
+	punct
+	literal
+)
+
+type token struct {
+	kind       tokenKind
+	start, end int
+}
+
+type javaParser struct {
+	src    []byte
+	tokens []token
+	pos    int
+}
+
+var errUnexpectedEOF = errors.New("unexpected end of file")
+
+func parseJava(src []byte) (*javaFile, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &javaParser{src: src, tokens: tokens}
+	result := &javaFile{}
+	for !p.eof() {
+		switch {
+		case p.is(";"):
+			p.pos++
+		case p.is("package") && len(result.types) == 0:
+			p.pos++
+			var b strings.Builder
+			for !p.eof() && !p.is(";") {
+				b.WriteString(p.text(p.tokens[p.pos]))
+				p.pos++
+			}
+			result.pkg = b.String()
+		case p.is("import"):
+			for !p.eof() && !p.is(";") {
+				p.pos++
+			}
+		default:
+			t := &javaType{}
+			if err := p.parseMember(t); err != nil {
+				return nil, err
+			}
+			result.types = append(result.types, t.types...)
+		}
+	}
+	return result, nil
+}
+
+func (p *javaParser) eof() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *javaParser) text(t token) string {
+	return string(p.src[t.start:t.end])
+}
+
+func (p *javaParser) is(s string) bool {
+	return !p.eof() && p.text(p.tokens[p.pos]) == s
+}
+
+func (p *javaParser) isAt(i int, s string) bool {
+	return i < len(p.tokens) && p.text(p.tokens[i]) == s
+}
+
+// parseBody parses the members of a type until its closing brace
+func (p *javaParser) parseBody(t *javaType) error {
+	for {
+		if p.eof() {
+			return errUnexpectedEOF
+		}
+		if p.is("}") {
+			p.pos++
+			return nil
+		}
+		if p.is(";") {
+			p.pos++
+			continue
+		}
+		if err := p.parseMember(t); err != nil {
+			return err
+		}
+	}
+}
+
+// parseMember parses a type, field, method, constructor or initializer
+// declaration and adds it to t
+func (p *javaParser) parseMember(t *javaType) error {
+	var head []token
+	depth := 0
+	for {
+		if p.eof() {
+			return errUnexpectedEOF
+		}
+		if p.is("@") && !p.isAt(p.pos+1, "interface") {
+			if err := p.skipAnnotation(); err != nil {
+				return err
+			}
+			continue
+		}
+		s := p.text(p.tokens[p.pos])
+		if depth == 0 && (s == "{" || s == ";" || s == "=") {
+			break
+		}
+		switch s {
+		case "(", "[":
+			depth++
+		case ")", "]":
+			depth--
+		}
+		head = append(head, p.tokens[p.pos])
+		p.pos++
+	}
+	terminator := p.text(p.tokens[p.pos])
+	paren := -1
+	for i, tok := range head {
+		s := p.text(tok)
+		if s == "(" {
+			paren = i
+			break
+		}
+		if i+1 < len(head) && p.isTypeKeyword(head, i) {
+			return p.parseType(t, head, i)
+		}
+	}
+	switch {
+	case paren > 0:
+		return p.parseMethod(t, head, paren)
+	case terminator == "{" && len(head) > 0 && p.text(head[len(head)-1]) == t.name:
+		// compact canonical constructor of a record
+		start := p.tokens[p.pos].start
+		if err := p.skipBalanced("{", "}"); err != nil {
+			return err
+		}
+		t.constructors = append(t.constructors, javaMember{
+			name:      t.name,
+			signature: []string{},
+			body:      string(p.src[start:p.tokens[p.pos-1].end]),
+			hasBody:   true,
+		})
+		return nil
+	case terminator == "{":
+		// initializer or unsupported declaration
+		return p.skipBalanced("{", "}")
+	}
+	return p.parseFields(t, head)
+}
+
+func (p *javaParser) isTypeKeyword(head []token, i int) bool {
+	switch p.text(head[i]) {
+	case "class", "interface", "enum":
+		return true
+	case "record":
+		return i+2 < len(head) && head[i+1].kind == word &&
+			(p.text(head[i+2]) == "(" || p.text(head[i+2]) == "<")
+	}
+	return false
+}
+
+func (p *javaParser) parseType(t *javaType, head []token, i int) error {
+	if !p.is("{") {
+		return errors.New("malformed type declaration")
+	}
+	p.pos++
+	nt := &javaType{name: p.text(head[i+1])}
+	keyword := p.text(head[i])
+	var list *[]string
+	var current strings.Builder
+	angles := 0
+	flush := func() {
+		if list != nil && current.Len() > 0 {
+			*list = append(*list, naming.RemoveGenerics(current.String()))
+		}
+		current.Reset()
+	}
+	for j := i + 2; j < len(head); j++ {
+		s := p.text(head[j])
+		switch {
+		case keyword == "record" && s == "(" && list == nil:
+			k, components := p.splitParameters(head, j)
+			for _, c := range components {
+				nt.fields = append(nt.fields, javaMember{
+					name:    c.name,
+					body:    c.text,
+					hasBody: true,
+				})
+			}
+			j = k
+		case angles == 0 && s == "extends":
+			flush()
+			list = &nt.extends
+		case angles == 0 && s == "implements":
+			flush()
+			list = &nt.implements
+		case angles == 0 && s == "permits":
+			flush()
+			list = nil
+		case angles == 0 && s == ",":
+			flush()
+		default:
+			if s == "<" {
+				angles++
+			} else if s == ">" {
+				angles--
+			}
+			current.WriteString(s)
+		}
+	}
+	flush()
+	if keyword == "enum" {
+		if err := p.parseEnumConstants(nt); err != nil {
+			return err
+		}
+	}
+	if err := p.parseBody(nt); err != nil {
+		return err
+	}
+	t.types = append(t.types, nt)
+	return nil
+}
+
+func (p *javaParser) parseEnumConstants(t *javaType) error {
+	for {
+		if p.eof() {
+			return errUnexpectedEOF
+		}
+		switch {
+		case p.is("}"):
+			return nil
+		case p.is(";"):
+			p.pos++
+			return nil
+		case p.is(","):
+			p.pos++
+		case p.is("@"):
+			if err := p.skipAnnotation(); err != nil {
+				return err
+			}
+		default:
+			start := p.tokens[p.pos]
+			name := p.text(start)
+			p.pos++
+			if p.is("(") {
+				if err := p.skipBalanced("(", ")"); err != nil {
+					return err
+				}
+			}
+			if p.is("{") {
+				if err := p.skipBalanced("{", "}"); err != nil {
+					return err
+				}
+			}
+			t.fields = append(t.fields, javaMember{
+				name:    name,
+				body:    string(p.src[start.start:p.tokens[p.pos-1].end]),
+				hasBody: true,
+			})
+		}
+	}
+}
+
+func (p *javaParser) parseMethod(t *javaType, head []token, paren int) error {
+	close, params := p.splitParameters(head, paren)
+	m := javaMember{name: p.text(head[paren-1]), signature: []string{}, parameters: []string{}}
+	for _, param := range params {
+		m.signature = append(m.signature, param.typ)
+		m.parameters = append(m.parameters, param.text)
+	}
+	isDefault := false
+	for j := close + 1; j < len(head); j++ {
+		if p.text(head[j]) == "default" {
+			isDefault = true
+		}
+	}
+	switch {
+	case p.is("{") && isDefault:
+		// annotation element with an array as default value
+		if err := p.skipBalanced("{", "}"); err != nil {
+			return err
+		}
+		p.skipUntil(";")
+	case p.is("{"):
+		start := p.tokens[p.pos].start
+		if err := p.skipBalanced("{", "}"); err != nil {
+			return err
+		}
+		m.body = string(p.src[start:p.tokens[p.pos-1].end])
+		m.hasBody = true
+	default:
+		p.skipUntil(";")
+	}
+	if m.name == t.name {
+		t.constructors = append(t.constructors, m)
+	} else {
+		t.methods = append(t.methods, m)
+	}
+	return nil
+}
+
+type javaParameter struct {
+	name, typ, text string
+}
+
+// splitParameters splits the tokens between head[open] and its matching
+// parenthesis, returning the index of the closing parenthesis
+func (p *javaParser) splitParameters(head []token, open int) (int, []javaParameter) {
+	var result []javaParameter
+	depth, first := 0, open+1
+	for j := open; j < len(head); j++ {
+		s := p.text(head[j])
+		switch s {
+		case "(", "<", "[":
+			depth++
+		case ")", ">", "]":
+			depth--
+		}
+		if (depth == 1 && s == ",") || depth == 0 {
+			if j > first {
+				if param, ok := p.parameter(head[first:j]); ok {
+					result = append(result, param)
+				}
+			}
+			first = j + 1
+		}
+		if depth == 0 {
+			return j, result
+		}
+	}
+	return len(head) - 1, result
+}
+
+func (p *javaParser) parameter(tokens []token) (javaParameter, bool) {
+	var tt []token
+	for _, tok := range tokens {
+		if p.text(tok) != "final" {
+			tt = append(tt, tok)
+		}
+	}
+	dims := ""
+	for len(tt) > 2 && p.text(tt[len(tt)-1]) == "]" && p.text(tt[len(tt)-2]) == "[" {
+		dims += "[]"
+		tt = tt[:len(tt)-2]
+	}
+	if len(tt) < 2 {
+		return javaParameter{}, false
+	}
+	name := p.text(tt[len(tt)-1])
+	if name == "this" {
+		return javaParameter{}, false
+	}
+	var typ strings.Builder
+	for _, tok := range tt[:len(tt)-1] {
+		typ.WriteString(p.text(tok))
+	}
+	t := naming.RemoveGenerics(typ.String())
+	t = strings.Replace(t, "...", "[]", -1)
+	if idx := strings.LastIndex(t, "."); idx != -1 {
+		t = t[idx+1:]
+	}
+	return javaParameter{
+		name: name,
+		typ:  t + dims,
+		text: strings.TrimSpace(string(p.src[tokens[0].start:tokens[len(tokens)-1].end])),
+	}, true
+}
+
+// parseFields parses a field declaration, whose head has already been
+// consumed, adding one member for each declarator
+func (p *javaParser) parseFields(t *javaType, head []token) error {
+	tokens := append([]token{}, head...)
+	depth := 0
+	for {
+		if p.eof() {
+			return errUnexpectedEOF
+		}
+		s := p.text(p.tokens[p.pos])
+		tokens = append(tokens, p.tokens[p.pos])
+		p.pos++
+		switch s {
+		case "(", "[", "{":
+			depth++
+		case ")", "]", "}":
+			depth--
+		}
+		if depth == 0 && s == ";" {
+			break
+		}
+	}
+	var declarators []int
+	depth, angles, inType := 0, 0, true
+	for j, tok := range tokens {
+		s := p.text(tok)
+		switch s {
+		case "(", "[", "{":
+			depth++
+		case ")", "]", "}":
+			depth--
+		case "<":
+			if inType {
+				angles++
+			}
+		case ">":
+			if inType {
+				angles--
+			}
+		}
+		if depth != 0 || angles != 0 || tok.kind != word {
+			continue
+		}
+		if (inType || p.text(tokens[j-1]) == ",") && p.isDeclarator(tokens, j) {
+			declarators = append(declarators, j)
+			inType = false
+		}
+	}
+	if len(declarators) == 0 {
+		return nil
+	}
+	// each field holds the shared modifiers and type followed by its own
+	// declarator, so that changing one of them does not change the others
+	typ := strings.TrimSpace(string(p.src[tokens[0].start:tokens[declarators[0]].start]))
+	for i, j := range declarators {
+		last := len(tokens) - 2
+		if i+1 < len(declarators) {
+			last = declarators[i+1] - 2
+		}
+		declarator := string(p.src[tokens[j].start:tokens[last].end])
+		t.fields = append(t.fields, javaMember{
+			name:    p.text(tokens[j]),
+			body:    strings.TrimSpace(typ+" "+declarator) + ";",
+			hasBody: true,
+		})
+	}
+	return nil
+}
+
+// isDeclarator reports whether tokens[i] is the name of a variable
+// declarator, i.e., whether it is followed by optional dimensions and
+// by an equal sign, a comma or a semicolon
+func (p *javaParser) isDeclarator(tokens []token, i int) bool {
+	j := i + 1
+	for j+1 < len(tokens) && p.text(tokens[j]) == "[" && p.text(tokens[j+1]) == "]" {
+		j += 2
+	}
+	if j >= len(tokens) {
+		return false
+	}
+	s := p.text(tokens[j])
+	return s == "=" || s == "," || s == ";"
+}
+
+func (p *javaParser) skipAnnotation() error {
+	p.pos++
+	for !p.eof() && p.tokens[p.pos].kind == word {
+		p.pos++
+		if !p.is(".") {
+			break
+		}
+		p.pos++
+	}
+	if p.is("(") {
+		return p.skipBalanced("(", ")")
+	}
+	return nil
+}
+
+// skipBalanced skips tokens from an opening delimiter until its matching
+// closing delimiter
+func (p *javaParser) skipBalanced(open, close string) error {
+	depth := 0
+	for ; !p.eof(); p.pos++ {
+		switch p.text(p.tokens[p.pos]) {
+		case open:
+			depth++
+		case close:
+			depth--
+			if depth == 0 {
+				p.pos++
+				return nil
+			}
+		}
+	}
+	return errUnexpectedEOF
+}
+
+func (p *javaParser) skipUntil(s string) {
+	for !p.eof() && !p.is(s) {
+		p.pos++
+	}
+	if !p.eof() {
+		p.pos++
+	}
+}
+
+func tokenize(src []byte) ([]token, error) {
+	var result []token
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f':
+			i++
+		case c == '/' && i+1 < len(src) && src[i+1] == '/':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(src) && src[i+1] == '*':
+			end := indexFrom(src, i+2, "*/")
+			if end == -1 {
+				return nil, errors.New("unterminated comment")
+			}
+			i = end + 2
+		case c == '"' && i+2 < len(src) && src[i+1] == '"' && src[i+2] == '"':
+			end := i + 3
+			for {
+				end = indexFrom(src, end, `"""`)
+				if end == -1 {
+					return nil, errors.New("unterminated text block")
+				}
+				if src[end-1] != '\\' {
+					break
+				}
+				end++
+			}
+			result = append(result, token{literal, i, end + 3})
+			i = end + 3
+		case c == '"' || c == '\'':
+			j := i + 1
+			for j < len(src) && src[j] != c && src[j] != '\n' {
+				if src[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(src) || src[j] != c {
+				return nil, errors.New("unterminated literal")
+			}
+			result = append(result, token{literal, i, j + 1})
+			i = j + 1
+		case isWordByte(c):
+			j := i + 1
+			for j < len(src) && isWordByte(src[j]) {
+				j++
+			}
+			result = append(result, token{word, i, j})
+			i = j
+		case c == '.' && i+2 < len(src) && src[i+1] == '.' && src[i+2] == '.':
+			result = append(result, token{punct, i, i + 3})
+			i += 3
+		default:
+			result = append(result, token{punct, i, i + 1})
+			i++
+		}
+	}
+	return result, nil
+}
+
+func isWordByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		c == '_' || c == '$' || c >= 0x80
+}
+
+func indexFrom(src []byte, from int, s string) int {
+	idx := strings.Index(string(src[from:]), s)
+	if idx == -1 {
+		return -1
+	}
+	return from + idx
+}
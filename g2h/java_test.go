@@ -0,0 +1,105 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestEntries(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []string
+	}{
+		{
+			"class with fields, methods and constructors",
+			`package p;
+			import java.util.List;
+			public class C extends B implements I, J<String> {
+				private int a = 1, b[];
+				public C(final int x, String... ys) { }
+				@Override
+				public <T> List<T> m(java.util.Map<String, T> m, int xs[]) { return null; }
+				abstract void n();
+			}`,
+			[]string{
+				"f.java/[CN]/C/[CS]/C(int,String[])/body\t{ }",
+				"f.java/[CN]/C/[CS]/C(int,String[])/parameters\tfinal int x|String... ys",
+				"f.java/[CN]/C/[FE]/a\tprivate int a = 1;",
+				"f.java/[CN]/C/[FE]/b\tprivate int b[];",
+				"f.java/[CN]/C/[MT]/m(Map,int[])/body\t{ return null; }",
+				"f.java/[CN]/C/[MT]/m(Map,int[])/parameters\tjava.util.Map<String, T> m|int xs[]",
+				"f.java/[CN]/C/[MT]/n()/parameters\t",
+				"f.java/[CN]/C/extend\tB",
+				"f.java/[CN]/C/implements\tI|J",
+				"f.java/package\tp",
+			},
+		},
+		{
+			"nested types, enums and initializers",
+			`class C {
+				static { int x = 0; }
+				Runnable r = new Runnable() { public void run() { } };
+				enum E { A, B(1) { void f() { } }; E() { } E(int i) { } }
+				interface I { void m(); }
+			}`,
+			[]string{
+				"f.java/[CN]/C/[CN]/E/[CS]/E()/body\t{ }",
+				"f.java/[CN]/C/[CN]/E/[CS]/E()/parameters\t",
+				"f.java/[CN]/C/[CN]/E/[CS]/E(int)/body\t{ }",
+				"f.java/[CN]/C/[CN]/E/[CS]/E(int)/parameters\tint i",
+				"f.java/[CN]/C/[CN]/E/[FE]/A\tA",
+				"f.java/[CN]/C/[CN]/E/[FE]/B\tB(1) { void f() { } }",
+				"f.java/[CN]/C/[CN]/I/[MT]/m()/parameters\t",
+				"f.java/[CN]/C/[FE]/r\tRunnable r = new Runnable() { public void run() { } };",
+			},
+		},
+		{
+			"comments and literals with delimiters",
+			`class C {
+				// }
+				/* { */
+				String s = "};{", t = "\"";
+				char c = '}';
+			}`,
+			[]string{
+				"f.java/[CN]/C/[FE]/c\tchar c = '}';",
+				"f.java/[CN]/C/[FE]/s\tString s = \"};{\";",
+				"f.java/[CN]/C/[FE]/t\tString t = \"\\\"\";",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			jf, err := parseJava([]byte(test.src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, e := range jf.entries("f.java") {
+				content := strings.Replace(strings.TrimSuffix(e.content, "\n"), "\n", "|", -1)
+				got = append(got, e.path+"\t"+content)
+			}
+			sort.Strings(got)
+			gotString := strings.Join(got, "\n")
+			wantString := strings.Join(test.want, "\n")
+			if gotString != wantString {
+				t.Errorf("Got\n%v\nwant\n%v", gotString, wantString)
+			}
+		})
+	}
+}
+
+func TestParseJavaErrors(t *testing.T) {
+	for _, src := range []string{
+		"class C {",
+		"class C { void m() { }",
+		"class C { String s = \"abc; }",
+		"class C { /* }",
+	} {
+		if _, err := parseJava([]byte(src)); err == nil {
+			t.Errorf("Expected error parsing %q", src)
+		}
+	}
+}
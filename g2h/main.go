@@ -0,0 +1,52 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path"
+)
+
+func main() {
+	refs := flag.String("refs", "refs/heads", "source references to convert")
+	verify := flag.Bool("verify", false,
+		"verify an existing historage repository instead of converting, printing a JSON report")
+	maxExamples := flag.Int("max-examples", 100, "maximum number of examples reported per anomaly")
+	flag.Parse()
+	if flag.NArg() < 2 {
+		fmt.Fprintf(os.Stderr,
+			"usage: %v [options] <source repository> <historage repository>\n",
+			path.Base(os.Args[0]))
+		os.Exit(1)
+	}
+	if *verify {
+		_, err := os.Stat(flag.Arg(1))
+		check(err, "could not open historage repository")
+	}
+	c, err := newConverter(flag.Arg(0), flag.Arg(1))
+	check(err, "could not open repositories")
+	if *verify {
+		r, err := c.verify(*refs, *maxExamples)
+		check(err, "could not verify repository")
+		status, err := r.write(os.Stdout)
+		check(err, "could not write report")
+		os.Exit(status)
+	}
+	n, err := c.convert(*refs)
+	check(err, "could not convert repository")
+	fmt.Fprintf(os.Stderr, "%v commits converted\n", n)
+	if n > 0 {
+		r, err := c.segment()
+		check(err, "could not compute the range of converted commits")
+		if r != "" {
+			fmt.Println(r)
+		}
+	}
+}
+
+func check(err error, info string) {
+	if err != nil {
+		log.Fatalf("%v: %v", info, err)
+	}
+}
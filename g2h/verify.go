@@ -0,0 +1,177 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/project-draco/naming"
+	"github.com/project-draco/pkg/entity"
+)
+
+const conversionLogSubject = "Add conversion log file"
+
+// report summarizes the verification of a Historage repository,
+// grouping anomalies by kind
+type report struct {
+	Source               string                `json:"source"`
+	Historage            string                `json:"historage"`
+	SourceCommits        int                   `json:"sourceCommits"`
+	HistorageCommits     int                   `json:"historageCommits"`
+	ConversionLogCommits int                   `json:"conversionLogCommits"`
+	Paths                int                   `json:"paths"`
+	Anomalies            map[string]*anomalies `json:"anomalies"`
+}
+
+type anomalies struct {
+	Count    int       `json:"count"`
+	Examples []anomaly `json:"examples"`
+}
+
+type anomaly struct {
+	Commit string `json:"commit,omitempty"`
+	Path   string `json:"path,omitempty"`
+	Detail string `json:"detail,omitempty"`
+}
+
+var memberRegexp = regexp.MustCompile(`^[^()/]+\([^()/]*\)$`)
+
+// verify checks that every source commit was converted, that every
+// Historage commit has author metadata, and that every path of the
+// Historage repository corresponds to an entity
+func (c *converter) verify(pattern string, maxExamples int) (*report, error) {
+	r := &report{Source: c.source, Historage: c.hr, Anomalies: map[string]*anomalies{}}
+	add := func(kind string, a anomaly) {
+		if r.Anomalies[kind] == nil {
+			r.Anomalies[kind] = &anomalies{Examples: []anomaly{}}
+		}
+		r.Anomalies[kind].Count++
+		if len(r.Anomalies[kind].Examples) < maxExamples {
+			r.Anomalies[kind].Examples = append(r.Anomalies[kind].Examples, a)
+		}
+	}
+	_, tips, err := c.references(pattern)
+	if err != nil {
+		return nil, err
+	}
+	out, err := runGit(c.source, append([]string{"rev-list"}, tips...)...)
+	if err != nil {
+		return nil, err
+	}
+	for _, hash := range strings.Fields(string(out)) {
+		r.SourceCommits++
+		if len(c.mapping) > 0 && c.mapping[hash] == "" {
+			add("unconverted-commit", anomaly{Commit: hash})
+		}
+	}
+	out, err = runGit(c.hr, "log", "--branches", "-z", "--name-only",
+		"--format=%H\x1f%an\x1f%ae\x1f%at\x1f%s")
+	if err != nil {
+		return nil, err
+	}
+	paths := map[string]bool{}
+	var current []string
+	for _, field := range strings.Split(string(out), "\x00") {
+		field = strings.TrimPrefix(field, "\n")
+		if field == "" {
+			continue
+		}
+		if strings.Contains(field, "\x1f") {
+			current = strings.SplitN(field, "\x1f", 5)
+			if current[4] == conversionLogSubject {
+				r.ConversionLogCommits++
+				continue
+			}
+			r.HistorageCommits++
+			if current[1] == "" {
+				add("missing-author-name", anomaly{Commit: current[0]})
+			}
+			if current[2] == "" {
+				add("missing-author-email", anomaly{Commit: current[0]})
+			}
+			if t, err := strconv.ParseInt(current[3], 10, 64); err != nil || t <= 0 {
+				add("missing-author-date", anomaly{Commit: current[0], Detail: current[3]})
+			}
+			continue
+		}
+		if paths[field] || current == nil || current[4] == conversionLogSubject {
+			continue
+		}
+		paths[field] = true
+		if kind, detail := checkPath(field); kind != "" {
+			add(kind, anomaly{Commit: current[0], Path: field, Detail: detail})
+		}
+	}
+	r.Paths = len(paths)
+	if r.SourceCommits != r.HistorageCommits {
+		add("commit-count", anomaly{
+			Detail: fmt.Sprintf("%v source commits, %v Historage commits",
+				r.SourceCommits, r.HistorageCommits),
+		})
+	}
+	return r, nil
+}
+
+// write writes the report as indented JSON, returning the exit status of
+// the verification, which is 1 if there is any anomaly
+func (r *report) write(w io.Writer) (int, error) {
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	if _, err := fmt.Fprintln(w, string(b)); err != nil {
+		return 0, err
+	}
+	if len(r.Anomalies) > 0 {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// checkPath returns the kind of anomaly of a Historage path, if any
+func checkPath(path string) (kind, detail string) {
+	if !utf8.ValidString(path) {
+		return "invalid-utf8", strconv.Quote(path)
+	}
+	if strings.ContainsRune(path, utf8.RuneError) {
+		return "replacement-character", ""
+	}
+	if strings.HasSuffix(path, ".java/package") {
+		return "", ""
+	}
+	if naming.FileFromHR(path) == "" {
+		return "unparseable-path", "no class directory"
+	}
+	segments := strings.Split(path[strings.Index(path, "/[CN]/")+1:], "/")
+	for i := 0; i < len(segments); i++ {
+		switch segments[i] {
+		case "[CN]", "[FE]":
+			if i+1 >= len(segments) || segments[i+1] == "" {
+				return "unparseable-path", "missing name after " + segments[i]
+			}
+			i++
+		case "[MT]", "[CS]":
+			if i+1 >= len(segments) || !memberRegexp.MatchString(segments[i+1]) {
+				return "malformed-member", "missing parameters after " + segments[i]
+			}
+			i++
+		case "body", "parameters", "extend", "implements":
+		default:
+			return "unparseable-path", "unexpected segment " + segments[i]
+		}
+	}
+	// the entity helpers panic on malformed members, so they are only
+	// called once the path structure is known to be valid
+	e := entity.Entity(path)
+	if e.QueryString() == "" {
+		return "unparseable-path", "no java file"
+	}
+	if e.Filename() == "" {
+		return "missing-filename", "entity helpers cannot derive the file name"
+	}
+	return "", ""
+}
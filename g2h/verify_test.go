@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCheckPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"src_p_C.java/package", ""},
+		{"src_p_C.java/[CN]/C/[FE]/f", ""},
+		{"src_p_C.java/[CN]/C/[CN]/D/[MT]/m(int,String[])/body", ""},
+		{"src_p_C.java/[CN]/C/[CS]/C()/parameters", ""},
+		{"src_p_C.java/[CN]/C/[MT]/m/body", "malformed-member"},
+		{"src_p_C.java/[CN]/C/[MT]/m(/body", "malformed-member"},
+		{"src_p_C.java/[CN]/C/other", "unparseable-path"},
+		{"README.md", "unparseable-path"},
+		{"src_p_\xff.java/[CN]/C/[FE]/f", "invalid-utf8"},
+		{"src_p_�.java/[CN]/C/[FE]/f", "replacement-character"},
+	}
+	for _, test := range tests {
+		if got, _ := checkPath(test.path); got != test.want {
+			t.Errorf("%q: expected %q but was %q", test.path, test.want, got)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	dir, err := ioutil.TempDir("", "g2h")
+	checkT(t, err)
+	defer os.RemoveAll(dir)
+	source, hr := filepath.Join(dir, "source"), filepath.Join(dir, "hr")
+	git(t, ".", "init", "--quiet", source)
+	commitFile(t, source, "src/p/C.java", "package p; class C { int f; }", "first")
+	commitFile(t, source, "src/p/C.java", "package p; class C { int f; void m() { } }", "second")
+	c, err := newConverter(source, hr)
+	checkT(t, err)
+	c.log = ioutil.Discard
+	_, err = c.convert("refs/heads")
+	checkT(t, err)
+
+	r, err := c.verify("refs/heads", 10)
+	checkT(t, err)
+	var out bytes.Buffer
+	status, err := r.write(&out)
+	checkT(t, err)
+	if status != 0 || r.SourceCommits != 2 || r.HistorageCommits != 2 || len(r.Anomalies) != 0 {
+		t.Fatalf("expected a converted repository without anomalies, got %v %v", status, out.String())
+	}
+
+	// a source commit left unconverted, a conversion log, a commit without
+	// author, and a commit of an anomalous path of each kind
+	commitFile(t, source, "src/p/D.java", "package p; class D { }", "third")
+	branch := git(t, hr, "for-each-ref", "--format=%(refname)", "refs/heads")
+	noAuthor := git(t, hr, "rev-parse", branch)
+	fastImport(t, hr, `commit `+branch+`
+author A <a@b> 1600000000 +0000
+committer A <a@b> 1600000000 +0000
+data 23
+Add conversion log file
+from `+noAuthor+`
+M 644 inline log.txt
+data 0
+commit `+branch+`
+author <> 0 +0000
+committer A <a@b> 1600000000 +0000
+data 9
+no author
+M 644 inline src_p_C.java/[CN]/C/[FE]/g
+data 0
+commit `+branch+`
+author A <a@b> 1600000000 +0000
+committer A <a@b> 1600000000 +0000
+data 5
+paths
+M 644 inline "src_p_\377.java/[CN]/C/[FE]/f"
+data 0
+M 644 inline src_p_�.java/[CN]/C/[FE]/f
+data 0
+M 644 inline README.md
+data 0
+M 644 inline src_p_C.java/[CN]/C/[MT]/m/body
+data 0
+M 644 inline C.java/[CN]/C/[FE]/f
+data 0
+`)
+	noAuthor = git(t, hr, "rev-parse", branch+"^")
+	paths := git(t, hr, "rev-parse", branch)
+	unconverted := git(t, source, "rev-parse", "HEAD")
+
+	c, err = newConverter(source, hr)
+	checkT(t, err)
+	r, err = c.verify("refs/heads", 10)
+	checkT(t, err)
+	out.Reset()
+	status, err = r.write(&out)
+	checkT(t, err)
+	if status != 1 {
+		t.Errorf("expected exit status 1, got %v", status)
+	}
+	var got report
+	checkT(t, json.Unmarshal(out.Bytes(), &got))
+	if got.SourceCommits != 3 || got.HistorageCommits != 4 || got.ConversionLogCommits != 1 {
+		t.Errorf("unexpected commit counts %+v", got)
+	}
+	want := map[string]*anomalies{
+		"commit-count":         {1, []anomaly{{Detail: "3 source commits, 4 Historage commits"}}},
+		"unconverted-commit":   {1, []anomaly{{Commit: unconverted}}},
+		"missing-author-name":  {1, []anomaly{{Commit: noAuthor}}},
+		"missing-author-email": {1, []anomaly{{Commit: noAuthor}}},
+		"missing-author-date":  {1, []anomaly{{Commit: noAuthor, Detail: "0"}}},
+		"invalid-utf8": {1, []anomaly{{Commit: paths, Path: "src_p_\ufffd.java/[CN]/C/[FE]/f",
+			Detail: `"src_p_\xff.java/[CN]/C/[FE]/f"`}}},
+		"replacement-character": {1, []anomaly{{Commit: paths, Path: "src_p_\uFFFD.java/[CN]/C/[FE]/f"}}},
+		"unparseable-path":      {1, []anomaly{{Commit: paths, Path: "README.md", Detail: "no class directory"}}},
+		"malformed-member": {1, []anomaly{{Commit: paths, Path: "src_p_C.java/[CN]/C/[MT]/m/body",
+			Detail: "missing parameters after [MT]"}}},
+		"missing-filename": {1, []anomaly{{Commit: paths, Path: "C.java/[CN]/C/[FE]/f",
+			Detail: "entity helpers cannot derive the file name"}}},
+	}
+	for kind, a := range want {
+		if !reflect.DeepEqual(got.Anomalies[kind], a) {
+			t.Errorf("%v: expected %+v, got %+v", kind, a, got.Anomalies[kind])
+		}
+	}
+	if len(got.Anomalies) != len(want) {
+		t.Errorf("expected %v kinds of anomalies, got %v", len(want), out.String())
+	}
+}
+
+func fastImport(t *testing.T, repo, stream string) {
+	cmd := exec.Command("git", "-C", repo, "fast-import", "--quiet")
+	cmd.Stdin = strings.NewReader(stream)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("%v: %s", err, out)
+	}
+}
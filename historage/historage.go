@@ -0,0 +1,46 @@
+// Package historage maps the entities of Historage repositories, such as
+// src_p_A.java/[CN]/A/[CN]/B/[MT]/m(), to the class, file and package they
+// belong to, so that the tools grouping entities into modules agree on them
+package historage
+
+import (
+	"strings"
+
+	"github.com/project-draco/naming"
+)
+
+// Class returns the innermost class of an entity, such as
+// src_p_A.java/[CN]/A/[CN]/B for src_p_A.java/[CN]/A/[CN]/B/[MT]/m(), the
+// entity itself if it is a class, or "" if it has no class, as files
+func Class(name string) string {
+	i := strings.LastIndex(name, "/[CN]/")
+	if i == -1 {
+		return ""
+	}
+	rest := name[i+len("/[CN]/"):]
+	if rest == "" {
+		return ""
+	}
+	j := strings.Index(rest, "/")
+	if j == -1 {
+		return name
+	}
+	return name[:i+len("/[CN]/")+j]
+}
+
+// File returns the Historage directory of the file of an entity, such as
+// src_p_A.java
+func File(name string) string {
+	return strings.TrimSuffix(naming.FileFromHR(name), "/[CN]/")
+}
+
+// Package returns the directory of the file of an entity, such as src_p
+// for src_p_A.java, which is the package when directories follow packages
+func Package(name string) string {
+	file := File(name)
+	i := strings.LastIndex(file, "_")
+	if i == -1 {
+		return ""
+	}
+	return file[:i]
+}
@@ -0,0 +1,28 @@
+package historage
+
+import "testing"
+
+func TestModules(t *testing.T) {
+	for _, test := range []struct {
+		name, class, file, pkg string
+	}{
+		{"src_p_A.java/[CN]/A/[CN]/B/[MT]/m()", "src_p_A.java/[CN]/A/[CN]/B", "src_p_A.java", "src_p"},
+		{"src_p_A.java/[CN]/A/[FE]/f", "src_p_A.java/[CN]/A", "src_p_A.java", "src_p"},
+		// classes, of pruning -level class, and files, of co-change
+		// -granularity coarse
+		{"src_p_A.java/[CN]/A", "src_p_A.java/[CN]/A", "src_p_A.java", "src_p"},
+		{"src_p_B.java/[CN]/", "", "src_p_B.java", "src_p"},
+		{"A.java/[CN]/A/[FE]/f", "A.java/[CN]/A", "A.java", ""},
+		{"README.md", "", "", ""},
+	} {
+		if got := Class(test.name); got != test.class {
+			t.Errorf("%v: expected class %q, got %q", test.name, test.class, got)
+		}
+		if got := File(test.name); got != test.file {
+			t.Errorf("%v: expected file %q, got %q", test.name, test.file, got)
+		}
+		if got := Package(test.name); got != test.pkg {
+			t.Errorf("%v: expected package %q, got %q", test.name, test.pkg, got)
+		}
+	}
+}
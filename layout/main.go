@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/project-draco/tools/historage"
+)
+
+func main() {
+	by := flag.String("by", "package", "package|file|class|regex, how entities are grouped")
+	mappingfile := flag.String("mapping", "", "file with a regular expression and a module per line, for regex")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: layout [options] < <mdg file> > <dot file>")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	var module moduleFunc
+	switch *by {
+	case "package":
+		module = historage.Package
+	case "file":
+		module = historage.File
+	case "class":
+		module = historage.Class
+	case "regex":
+		if *mappingfile == "" {
+			log.Fatal("regex requires a mapping")
+		}
+		f, err := os.Open(*mappingfile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		m, err := readMapping(f)
+		f.Close()
+		if err != nil {
+			log.Fatalf("%v: %v", *mappingfile, err)
+		}
+		module = m.module
+	default:
+		log.Fatalf("invalid grouping: %v", *by)
+	}
+	data, err := ioutil.ReadAll(os.Stdin)
+	if err != nil {
+		log.Fatal(err)
+	}
+	entities, err := readEntities(bytes.NewReader(data))
+	if err != nil {
+		log.Fatal(err)
+	}
+	modules, members, without := group(entities, module)
+	if len(without) > 0 {
+		fmt.Fprintf(os.Stderr, "%v of %v entities without a %v are in clusters of their own\n", len(without), len(entities), *by)
+	}
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "// layout=%v", *by)
+	if *by == "regex" {
+		fmt.Fprintf(&buf, " mapping=%v", *mappingfile)
+	}
+	fmt.Fprintf(&buf, "\n// mdg-sha256=%x\n", sha256.Sum256(data))
+	fmt.Fprintf(&buf, "// entities=%v clusters=%v\n", len(entities), len(modules))
+	buf.WriteString("digraph {\n")
+	for i, m := range modules {
+		fmt.Fprintf(&buf, "subgraph cluster%v {\nlabel=%q;\n", i, m)
+		for _, e := range members[m] {
+			fmt.Fprintf(&buf, "\"%v\";\n", e)
+		}
+		buf.WriteString("}\n")
+	}
+	buf.WriteString("}\n")
+	fmt.Print(buf.String())
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	scanner "github.com/project-draco/pkg/dependency-scanner"
+)
+
+// moduleFunc returns the module of an entity, or "" if it has none
+type moduleFunc func(name string) string
+
+// mapping is a list of regular expressions, each with the template of the
+// module of the entities it matches
+type mapping struct {
+	patterns  []*regexp.Regexp
+	templates []string
+}
+
+// readMapping reads lines with a regular expression and a module, which may
+// refer to the submatches of the regular expression as $1, $2 and so on.
+// Blank lines and lines starting with # are ignored
+func readMapping(r io.Reader) (*mapping, error) {
+	m := &mapping{}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		t := strings.TrimSpace(scanner.Text())
+		if t == "" || strings.HasPrefix(t, "#") {
+			continue
+		}
+		fields := strings.Fields(t)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("line %v: expected a regular expression and a module", line)
+		}
+		re, err := regexp.Compile(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %v: %v", line, err)
+		}
+		m.patterns = append(m.patterns, re)
+		m.templates = append(m.templates, fields[1])
+	}
+	return m, scanner.Err()
+}
+
+// module returns the module of the first regular expression matching the
+// entity, or "" if none does
+func (m *mapping) module(name string) string {
+	for i, re := range m.patterns {
+		if match := re.FindStringSubmatchIndex(name); match != nil {
+			return string(re.ExpandString(nil, m.templates[i], name, match))
+		}
+	}
+	return ""
+}
+
+// readEntities returns the entities of a MDG in order of appearance, as
+// clustering numbers them
+func readEntities(r io.Reader) ([]string, error) {
+	var entities []string
+	seen := map[string]bool{}
+	s := scanner.NewDependencyScanner(r)
+	for s.Scan() {
+		dep := s.Dependency()
+		for _, e := range append(dep.From, dep.To) {
+			if !seen[e] {
+				seen[e] = true
+				entities = append(entities, e)
+			}
+		}
+	}
+	return entities, s.Err()
+}
+
+// group returns the module of each entity, in order of appearance, and the
+// entities of each one. Entities without a module are each in a module of
+// their own, named after them, and are returned apart
+func group(entities []string, module moduleFunc) (modules []string, members map[string][]string, without []string) {
+	members = map[string][]string{}
+	for _, e := range entities {
+		m := module(e)
+		if m == "" {
+			without = append(without, e)
+			m = e
+		}
+		if _, ok := members[m]; !ok {
+			modules = append(modules, m)
+		}
+		members[m] = append(members[m], e)
+	}
+	return modules, members, without
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/project-draco/tools/historage"
+)
+
+func TestGroup(t *testing.T) {
+	m, err := readMapping(strings.NewReader("# layers\n^src_p[01]_ low\n^src_p(\\d)_ p$1\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	entities := []string{"src_p2_A.java/[CN]/A", "src_p0_B.java/[CN]/B", "n1", "src_p1_C.java/[CN]/C"}
+	modules, members, without := group(entities, m.module)
+	if want := []string{"p2", "low", "n1"}; !reflect.DeepEqual(modules, want) {
+		t.Errorf("expected modules %v, got %v", want, modules)
+	}
+	if want := []string{"src_p0_B.java/[CN]/B", "src_p1_C.java/[CN]/C"}; !reflect.DeepEqual(members["low"], want) {
+		t.Errorf("expected members %v, got %v", want, members["low"])
+	}
+	if want := []string{"n1"}; !reflect.DeepEqual(without, want) {
+		t.Errorf("expected %v without a module, got %v", want, without)
+	}
+}
+
+func TestGroupModuleMDGs(t *testing.T) {
+	for _, test := range []struct {
+		name, mdg string
+		module    moduleFunc
+		modules   []string
+		without   []string
+	}{
+		// files are the consequents of co-change -granularity coarse
+		{"coarse by class",
+			"src_p_A.java/[CN]/A/[MT]/m()\tsrc_p_B.java/[CN]/\t2\t1\n",
+			historage.Class, []string{"src_p_A.java/[CN]/A", "src_p_B.java/[CN]/"}, []string{"src_p_B.java/[CN]/"}},
+		{"coarse by file",
+			"src_p_A.java/[CN]/A/[MT]/m()\tsrc_p_B.java/[CN]/\t2\t1\n",
+			historage.File, []string{"src_p_A.java", "src_p_B.java"}, nil},
+		// classes are the entities of pruning -level class
+		{"classes by class",
+			"src_p_A.java/[CN]/A\tsrc_q_B.java/[CN]/B/[CN]/Inner\t2\n",
+			historage.Class, []string{"src_p_A.java/[CN]/A", "src_q_B.java/[CN]/B/[CN]/Inner"}, nil},
+		{"classes by package",
+			"src_p_A.java/[CN]/A\tsrc_q_B.java/[CN]/B/[CN]/Inner\t2\n",
+			historage.Package, []string{"src_p", "src_q"}, nil},
+	} {
+		entities, err := readEntities(strings.NewReader(test.mdg))
+		if err != nil {
+			t.Fatal(err)
+		}
+		modules, _, without := group(entities, test.module)
+		if !reflect.DeepEqual(modules, test.modules) || !reflect.DeepEqual(without, test.without) {
+			t.Errorf("%v: expected %v and %v without a module, got %v and %v",
+				test.name, test.modules, test.without, modules, without)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+// Package history walks the commits of a Historage repository as
+// co-change mines them, so that other tools count the same commits of
+// each entity that co-change does
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"math"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/project-draco/naming"
+)
+
+// Options select the commits mined and the entities of each commit
+type Options struct {
+	// Range is a git commits range, as HEAD~100..HEAD
+	Range string
+	// MaxAge is the age, as [Y][M][D], of the oldest commit mined,
+	// relative to the last one
+	MaxAge string
+	// Limit is the maximum number of commits walked
+	Limit int
+	// Max is the maximum number of entities of a commit counted
+	Max int
+	// Ignore and Filter are regular expressions of the entities ignored
+	// and of the only ones considered, if not empty
+	Ignore, Filter string
+	// Granularity of consequents, fine or coarse
+	Granularity string
+	// CountFiles counts the commits of the files too, which are the
+	// entities of coarse MDGs, along with those of their entities
+	CountFiles bool
+}
+
+// DefaultOptions are the defaults of co-change
+var DefaultOptions = Options{Limit: math.MaxInt64, Max: 50, Granularity: "fine"}
+
+// Git runs a git command, given without "git", returning its output
+type Git func(args ...string) ([]byte, error)
+
+// Command returns a Git running git in dir, or in the current directory if
+// dir is empty
+func Command(dir string) Git {
+	return func(args ...string) ([]byte, error) {
+		if dir != "" {
+			args = append([]string{"-C", dir}, args...)
+		}
+		cmd := exec.Command("git", args...)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("git %v: %v: %v", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+		}
+		return out, nil
+	}
+}
+
+// History is a Historage repository mined with some options
+type History struct {
+	git     Git
+	options Options
+	ignore  *regexp.Regexp
+	filter  *regexp.Regexp
+}
+
+var suffixRegexp = regexp.MustCompile(`\/body$|\/parameters$`)
+
+// New returns the history of the repository run by git
+func New(git Git, o Options) (*History, error) {
+	h := &History{git: git, options: o}
+	var err error
+	if o.Ignore != "" {
+		if h.ignore, err = regexp.Compile(o.Ignore); err != nil {
+			return nil, err
+		}
+	}
+	if o.Filter != "" {
+		if h.filter, err = regexp.Compile(o.Filter); err != nil {
+			return nil, err
+		}
+	}
+	if o.Granularity != "fine" && o.Granularity != "coarse" {
+		return nil, fmt.Errorf("invalid granularity: %v", o.Granularity)
+	}
+	return h, nil
+}
+
+// Commits returns the hashes of the commits of the range and maximum age,
+// oldest first
+func (h *History) Commits() ([]string, error) {
+	args := []string{"log", "--date=iso", "--reverse", "--pretty=format:%H"}
+	if h.options.MaxAge != "" {
+		since, err := h.since()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, since)
+	}
+	if h.options.Range != "" {
+		args = append(args, h.options.Range)
+	}
+	out, err := h.git(args...)
+	if err != nil {
+		return nil, err
+	}
+	return lines(out)
+}
+
+func (h *History) since() (string, error) {
+	years, months, days := 0, 0, 0
+	re := regexp.MustCompile(`((\d)+Y)?((\d)+M)?((\d)+D)?`)
+	submatch := re.FindStringSubmatch(h.options.MaxAge)
+	pointer := []*int{&years, &months, &days}
+	for i := 1; i < len(submatch); i += 2 {
+		if submatch[i+1] != "" {
+			var err error
+			*pointer[(i-1)/2], err = strconv.Atoi(submatch[i+1])
+			if err != nil {
+				return "", err
+			}
+		}
+	}
+	out, err := h.git("log", "-n 1", "--pretty=format:%aI")
+	if err != nil {
+		return "", err
+	}
+	iso8601 := "2006-01-02T15:04:05-07:00"
+	lastCommitDate, err := time.Parse(iso8601, string(out))
+	if err != nil {
+		return "", err
+	}
+	lastCommitDate = lastCommitDate.AddDate(-years, -months, -days)
+	return fmt.Sprintf("--since=%v", lastCommitDate.Format(iso8601)), nil
+}
+
+// Changes returns the entities modified and deleted by a commit, without
+// the /body and /parameters suffixes, and leaving out package and extend
+// files and the entities ignored or not filtered
+func (h *History) Changes(commit string) (map[string]struct{}, []string, error) {
+	out, err := h.git("diff-tree", "--no-commit-id", "--name-status", "-r", commit)
+	if err != nil {
+		return nil, nil, err
+	}
+	ll, err := lines(out)
+	if err != nil {
+		return nil, nil, err
+	}
+	var modified map[string]struct{}
+	var deleted []string
+	for _, line := range ll {
+		if len(line) < 2 ||
+			strings.HasSuffix(line, "/package") ||
+			strings.HasSuffix(line, "/extend") {
+			continue
+		}
+		entity := suffixRegexp.ReplaceAllString(line[2:], "")
+		if (h.ignore == nil || !h.ignore.MatchString(entity)) &&
+			(h.filter == nil || h.filter.MatchString(entity)) {
+			if strings.HasPrefix(line, "D\t") {
+				deleted = append(deleted, entity)
+			} else {
+				if modified == nil {
+					modified = map[string]struct{}{}
+				}
+				modified[entity] = struct{}{}
+			}
+		}
+	}
+	return modified, deleted, nil
+}
+
+// Walk walks the commits up to the limit, calling f with the changes of
+// each one, and counts the commits changing from two to max entities and
+// the number of them changing each entity, forgetting the counts of the
+// entities deleted afterwards. The commits of a file are counted too if
+// CountFiles is set
+func (h *History) Walk(commits []string, f func(commit string, modified map[string]struct{}, deleted []string, counted bool)) (map[string]int, int, error) {
+	counts := map[string]int{}
+	total := 0
+	for i, c := range commits {
+		if i >= h.options.Limit {
+			break
+		}
+		modified, deleted, err := h.Changes(c)
+		if err != nil {
+			return nil, 0, err
+		}
+		counted := len(modified) <= h.options.Max && len(modified) > 1
+		if counted {
+			total++
+			files := map[string]bool{}
+			for m := range modified {
+				counts[m]++
+				if file := naming.FileFromHR(m); h.options.CountFiles && file != "" {
+					files[file] = true
+				}
+			}
+			for file := range files {
+				counts[file]++
+			}
+		}
+		if f != nil {
+			f(c, modified, deleted, counted)
+		}
+		for _, d := range deleted {
+			delete(counts, d)
+		}
+	}
+	return counts, total, nil
+}
+
+// Count returns the counts of Walk for all commits
+func (h *History) Count() (map[string]int, int, error) {
+	commits, err := h.Commits()
+	if err != nil {
+		return nil, 0, err
+	}
+	return h.Walk(commits, nil)
+}
+
+// Fingerprint identifies the commits mined and the options affecting
+// which entities are counted in each commit
+func (h *History) Fingerprint() (string, error) {
+	out, err := h.git("rev-parse", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	o := h.options
+	return fmt.Sprintf("# co-change head=%v range=%q max-age=%q limit=%v max=%v ignore=%q filter=%q granularity=%v",
+		strings.TrimSpace(string(out)), o.Range, o.MaxAge, o.Limit, o.Max, o.Ignore, o.Filter, o.Granularity), nil
+}
+
+func lines(b []byte) ([]string, error) {
+	var result []string
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		result = append(result, scanner.Text())
+	}
+	return result, scanner.Err()
+}
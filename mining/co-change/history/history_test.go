@@ -0,0 +1,99 @@
+package history
+
+import (
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWalk(t *testing.T) {
+	dir, err := ioutil.TempDir("", "history")
+	checkT(t, err)
+	defer os.RemoveAll(dir)
+	git := Command(dir)
+	run(t, git, "init", "--quiet")
+	// the root commit is not counted, as diff-tree lists no changes of it
+	commit(t, git, map[string]string{"f1.java/[CN]/C/[MT]/m1()/body": "1"}, nil)
+	commit(t, git, map[string]string{
+		"f1.java/[CN]/C/[MT]/m1()/body":       "2",
+		"f1.java/[CN]/C/[MT]/m1()/parameters": "2",
+		"f2.java/[CN]/D/[FE]/g":               "2",
+		"f1.java/package":                     "2",
+	}, nil)
+	commit(t, git, map[string]string{
+		"f1.java/[CN]/C/[MT]/m1()/body": "3",
+		"f1.java/[CN]/C/[FE]/f":         "3",
+		"test.java/[CN]/T/[FE]/t":       "3",
+	}, nil)
+	commit(t, git, map[string]string{"f1.java/[CN]/C/[MT]/m1()/body": "4"}, []string{"f1.java/[CN]/C/[FE]/f"})
+
+	for _, test := range []struct {
+		options Options
+		counts  map[string]int
+		total   int
+	}{
+		{DefaultOptions, map[string]int{
+			"f1.java/[CN]/C/[MT]/m1()": 2, "f2.java/[CN]/D/[FE]/g": 1, "test.java/[CN]/T/[FE]/t": 1,
+		}, 2},
+		{Options{Limit: 3, Max: 2, Ignore: "^test", Granularity: "coarse", CountFiles: true}, map[string]int{
+			"f1.java/[CN]/C/[MT]/m1()": 2, "f2.java/[CN]/D/[FE]/g": 1,
+			// the limit stops before f is deleted
+			"f1.java/[CN]/C/[FE]/f": 1,
+			"f1.java/[CN]/":         2, "f2.java/[CN]/": 1,
+		}, 2},
+		{Options{Limit: 2, Max: 50, Filter: "^f1", Granularity: "fine"}, map[string]int{}, 0},
+		// co-change counts only the entities, whatever the granularity
+		{Options{Limit: math.MaxInt64, Max: 50, Granularity: "coarse"}, map[string]int{
+			"f1.java/[CN]/C/[MT]/m1()": 2, "f2.java/[CN]/D/[FE]/g": 1, "test.java/[CN]/T/[FE]/t": 1,
+		}, 2},
+	} {
+		h, err := New(git, test.options)
+		checkT(t, err)
+		counts, total, err := h.Count()
+		checkT(t, err)
+		if !reflect.DeepEqual(counts, test.counts) || total != test.total {
+			t.Errorf("%+v: got %v %v, want %v %v", test.options, counts, total, test.counts, test.total)
+		}
+	}
+
+	h, err := New(git, DefaultOptions)
+	checkT(t, err)
+	fingerprint, err := h.Fingerprint()
+	checkT(t, err)
+	head := run(t, git, "rev-parse", "HEAD")
+	if !strings.HasPrefix(fingerprint, "# co-change head="+head+" ") {
+		t.Errorf("unexpected fingerprint %v", fingerprint)
+	}
+}
+
+func commit(t *testing.T, git Git, files map[string]string, deleted []string) {
+	for path, content := range files {
+		checkT(t, os.MkdirAll(filepath.Dir(filepath.Join(dirOf(t, git), path)), 0755))
+		checkT(t, ioutil.WriteFile(filepath.Join(dirOf(t, git), path), []byte(content), 0644))
+	}
+	run(t, git, "add", "-A")
+	for _, path := range deleted {
+		run(t, git, "rm", "--quiet", path)
+	}
+	run(t, git, "-c", "user.name=A", "-c", "user.email=a@b", "commit", "--quiet", "-m", "commit")
+}
+
+func dirOf(t *testing.T, git Git) string {
+	return run(t, git, "rev-parse", "--show-toplevel")
+}
+
+func run(t *testing.T, git Git, args ...string) string {
+	out, err := git(args...)
+	checkT(t, err)
+	return strings.TrimSpace(string(out))
+}
+
+func checkT(t *testing.T, err error) {
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -9,15 +9,13 @@ import (
 	"math"
 	"os"
 	"os/exec"
-	"regexp"
 	"runtime/pprof"
 	"sort"
-	"strconv"
 	"strings"
 	"sync"
-	"time"
 
 	"github.com/project-draco/naming"
+	"github.com/project-draco/tools/mining/co-change/history"
 )
 
 type rule struct {
@@ -60,6 +58,7 @@ var (
 	commitsMaximumAge = flag.String("max-age", "", "[Y][M][D]")
 	commitsRange      = flag.String("range", "", "commits range")
 	filter            = flag.String("filter", "", "regex used to filter file names")
+	header            = flag.Bool("header", false, "Print a # line identifying the history mined")
 	mu                sync.Mutex
 )
 
@@ -76,7 +75,14 @@ func main() {
 }
 
 func collect() {
-	commits := gitLog()
+	h, err := history.New(git, options())
+	if err != nil {
+		panic(err)
+	}
+	commits, err := h.Commits()
+	if err != nil {
+		panic(err)
+	}
 	if len(commits) < *minCommits {
 		return
 	}
@@ -84,64 +90,43 @@ func collect() {
 	coarseGrainedRules := rules{}
 	conjunctiveFineGrainedRules := rules{}
 	conjunctiveCoarseGrainedRules := rules{}
-	commitsCountByAntecedents := map[string]int{}
 	commitsByFineGrainedRule := map[ruleAsString]set{}
 	commitsByCoarseGrainedRule := map[ruleAsString]set{}
 	adjacencyList := map[string]set{}
-	commitsCount := 0
-	regexpToReplace := regexp.MustCompile(`\/body$|\/parameters$`)
-	regexpToIgnore := regexp.MustCompile(*ignore)
-	regexpToFilter := regexp.MustCompile(*filter)
-	if *ignore == "" {
-		regexpToIgnore = nil
-	}
-	if *filter == "" {
-		regexpToFilter = nil
-	}
-	for i, c := range commits {
-		if i >= *limit {
-			break
-		}
-		modified, deleted := gitDiffTree(c, regexpToReplace, regexpToIgnore, regexpToFilter)
-		if len(modified) <= *maxCommitLength && len(modified) > 1 {
-			commitsCount++
-			for m := range modified {
-				commitsCountByAntecedents[m]++
-			}
-			if *output == "rules" || *output == "rules-and-commits" {
-				fgr, cgr := addRules(
-					fineGrainedRules,
-					coarseGrainedRules,
-					adjacencyList,
-					modified,
-				)
-				if *aggregationLevel > 1 {
-					ch := make(chan ruleWithCount)
-					go aggregate(ch, fgr, rules{}, rules{},
-						map[string]int{}, map[string]set{}, 1, 0, 0, 0)
-					for rc := range ch {
-						conjunctiveFineGrainedRules[rc.r.asString()]++
-					}
-					ch = make(chan ruleWithCount)
-					go aggregate(ch, cgr, rules{}, rules{},
-						map[string]int{}, map[string]set{}, 1, 0, 0, 0)
-					for rc := range ch {
-						conjunctiveCoarseGrainedRules[rc.r.asString()]++
-					}
-				}
-				for r := range fgr {
-					commitsByFineGrainedRule[r] = commitsByFineGrainedRule[r].add(c)
+	commitsCountByAntecedents, commitsCount, err := h.Walk(commits, func(c string, modified map[string]struct{}, deleted []string, counted bool) {
+		if counted && (*output == "rules" || *output == "rules-and-commits") {
+			fgr, cgr := addRules(
+				fineGrainedRules,
+				coarseGrainedRules,
+				adjacencyList,
+				modified,
+			)
+			if *aggregationLevel > 1 {
+				ch := make(chan ruleWithCount)
+				go aggregate(ch, fgr, rules{}, rules{},
+					map[string]int{}, map[string]set{}, 1, 0, 0, 0)
+				for rc := range ch {
+					conjunctiveFineGrainedRules[rc.r.asString()]++
 				}
-				for r := range cgr {
-					commitsByCoarseGrainedRule[r] = commitsByCoarseGrainedRule[r].add(c)
+				ch = make(chan ruleWithCount)
+				go aggregate(ch, cgr, rules{}, rules{},
+					map[string]int{}, map[string]set{}, 1, 0, 0, 0)
+				for rc := range ch {
+					conjunctiveCoarseGrainedRules[rc.r.asString()]++
 				}
 			}
-		}
-		for _, d := range deleted {
-			delete(commitsCountByAntecedents, d)
+			for r := range fgr {
+				commitsByFineGrainedRule[r] = commitsByFineGrainedRule[r].add(c)
+			}
+			for r := range cgr {
+				commitsByCoarseGrainedRule[r] = commitsByCoarseGrainedRule[r].add(c)
+			}
 		}
 		deleteRules(fineGrainedRules, deleted)
 		deleteRules(coarseGrainedRules, deleted)
+	})
+	if err != nil {
+		panic(err)
 	}
 	var (
 		rr, cr        rules
@@ -178,75 +163,22 @@ func collect() {
 	}
 }
 
-func gitLog() (commits []string) {
-	since := ""
-	if *commitsMaximumAge != "" {
-		years, months, days := 0, 0, 0
-		re := regexp.MustCompile(`((\d)+Y)?((\d)+M)?((\d)+D)?`)
-		submatch := re.FindStringSubmatch(*commitsMaximumAge)
-		pointer := []*int{&years, &months, &days}
-		for i := 1; i < len(submatch); i += 2 {
-			if submatch[i+1] != "" {
-				var err error
-				*pointer[(i-1)/2], err = strconv.Atoi(submatch[i+1])
-				if err != nil {
-					panic(err)
-				}
-			}
-		}
-		lastCommitDateAsString := string(executorFunc(
-			[]string{"git", "log", "-n 1", "--pretty=format:%aI"},
-		))
-		iso8601 := "2006-01-02T15:04:05-07:00"
-		lastCommitDate, err := time.Parse(iso8601, lastCommitDateAsString)
-		if err != nil {
-			panic(err)
-		}
-		lastCommitDate = lastCommitDate.AddDate(-years, -months, -days)
-		since = fmt.Sprintf("--since=%v", lastCommitDate.Format(iso8601))
-	}
-	args := []string{
-		"git", "log", "--date=iso", "--reverse", "--pretty=format:%H",
-	}
-	if since != "" {
-		args = append(args, since)
-	}
-	if *commitsRange != "" {
-		args = append(args, *commitsRange)
+// options are the options of history given by the flags
+func options() history.Options {
+	return history.Options{
+		Range:       *commitsRange,
+		MaxAge:      *commitsMaximumAge,
+		Limit:       *limit,
+		Max:         *maxCommitLength,
+		Ignore:      *ignore,
+		Filter:      *filter,
+		Granularity: *granularity,
 	}
-	for line := range scanOutput(executorFunc(args)) {
-		commits = append(commits, line)
-	}
-	return
 }
 
-func gitDiffTree(
-	commit string,
-	regexpToReplace, regexpToIgnore, regexpToFilter *regexp.Regexp,
-) (
-	modified set,
-	deleted []string,
-) {
-	args := []string{
-		"git", "diff-tree", "--no-commit-id", "--name-status", "-r", commit,
-	}
-	for line := range scanOutput(executorFunc(args)) {
-		if len(line) < 2 ||
-			strings.HasSuffix(line, "/package") ||
-			strings.HasSuffix(line, "/extend") {
-			continue
-		}
-		entity := regexpToReplace.ReplaceAllString(line[2:], "")
-		if (regexpToIgnore == nil || !regexpToIgnore.MatchString(entity)) &&
-			(regexpToFilter == nil || regexpToFilter.MatchString(entity)) {
-			if strings.HasPrefix(line, "D	") {
-				deleted = append(deleted, entity)
-			} else {
-				modified = modified.add(entity)
-			}
-		}
-	}
-	return
+// git runs git commands with executorFunc
+func git(args ...string) ([]byte, error) {
+	return executorFunc(append([]string{"git"}, args...)), nil
 }
 
 func addRules(
@@ -401,6 +333,17 @@ func printOutput(
 	commitsCount int,
 	commitsByRule map[ruleAsString]set,
 ) {
+	if *header {
+		h, err := history.New(git, options())
+		if err != nil {
+			panic(err)
+		}
+		fingerprint, err := h.Fingerprint()
+		if err != nil {
+			panic(err)
+		}
+		fmt.Fprintln(out, fingerprint)
+	}
 	switch *output {
 	case "count":
 		for k, v := range commitsCountByAntecedents {
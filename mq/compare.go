@@ -0,0 +1,395 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+)
+
+// comparison is how similar a decomposition is to a reference one.
+// MoJo, MoJoFM and ARI compare only the entities of both, and a2a all of
+// them
+type comparison struct {
+	Entities   int     `json:"entities"`
+	OnlyFirst  int     `json:"onlyFirst"`
+	OnlySecond int     `json:"onlySecond"`
+	MoJo       int     `json:"mojo"`
+	MoJoFM     float64 `json:"mojoFM"`
+	A2a        float64 `json:"a2a"`
+	ARI        float64 `json:"ari"`
+}
+
+// compare runs the compare subcommand, which reports all metrics, or the
+// subcommand of a single metric, which prints only its value
+func compare(command string, args []string) {
+	fs := flag.NewFlagSet(command, flag.ExitOnError)
+	format := fs.String("format", "text", "text|json, for compare")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: mq %v [options] <dot file> <reference dot file>\n", command)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	a, err := readDecompositionFile(fs.Arg(0))
+	check(err)
+	b, err := readDecompositionFile(fs.Arg(1))
+	check(err)
+	r := compareDecompositions(a, b)
+	if r.OnlyFirst > 0 || r.OnlySecond > 0 {
+		fmt.Fprintf(os.Stderr, "%v entities only in %v and %v only in %v; MoJo, MoJoFM and ARI compare the other %v\n",
+			r.OnlyFirst, fs.Arg(0), r.OnlySecond, fs.Arg(1), r.Entities)
+	}
+	switch command {
+	case "mojo":
+		fmt.Println(r.MoJo)
+	case "mojofm":
+		fmt.Println(r.MoJoFM)
+	case "a2a":
+		fmt.Println(r.A2a)
+	case "ari":
+		fmt.Println(r.ARI)
+	default:
+		switch *format {
+		case "text":
+			fmt.Printf("entities\t%v\nmojo\t%v\nmojofm\t%v\na2a\t%v\nari\t%v\n", r.Entities, r.MoJo, r.MoJoFM, r.A2a, r.ARI)
+		case "json":
+			data, err := json.MarshalIndent(r, "", "  ")
+			check(err)
+			fmt.Println(string(data))
+		default:
+			check(fmt.Errorf("invalid format: %v", *format))
+		}
+	}
+}
+
+func compareDecompositions(a, b decomposition) comparison {
+	c := newContingency(a, b)
+	r := comparison{
+		Entities:   c.n,
+		OnlyFirst:  len(a) - c.n,
+		OnlySecond: len(b) - c.n,
+		MoJo:       c.mno(),
+		MoJoFM:     c.mojoFM(),
+		A2a:        c.a2a(),
+		ARI:        c.ari(),
+	}
+	if t := c.transpose().mno(); t < r.MoJo {
+		r.MoJo = t
+	}
+	return r
+}
+
+// contingency is the number of entities of both decompositions in each
+// pair of their clusters, the rows being the clusters of the first one
+// and the columns the clusters of the second one
+type contingency struct {
+	counts []map[int]int
+	// rows and cols are the number of entities of both decompositions in
+	// each cluster, and n their total
+	rows, cols []int
+	n          int
+	// clusters are the numbers of clusters, and entities the numbers of
+	// entities, of each decomposition, including the entities of only one
+	clusters, entities [2]int
+}
+
+func newContingency(a, b decomposition) *contingency {
+	ca, cb := a.clusters(), b.clusters()
+	c := &contingency{
+		counts:   make([]map[int]int, len(ca)),
+		rows:     make([]int, len(ca)),
+		cols:     make([]int, len(cb)),
+		clusters: [2]int{len(ca), len(cb)},
+		entities: [2]int{len(a), len(b)},
+	}
+	ia, ib := indexes(ca), indexes(cb)
+	for i := range c.counts {
+		c.counts[i] = map[int]int{}
+	}
+	for e, k := range a {
+		l, ok := b[e]
+		if !ok {
+			continue
+		}
+		i, j := ia[k], ib[l]
+		c.counts[i][j]++
+		c.rows[i]++
+		c.cols[j]++
+		c.n++
+	}
+	return c
+}
+
+func indexes(names []string) map[string]int {
+	m := map[string]int{}
+	for i, name := range names {
+		m[name] = i
+	}
+	return m
+}
+
+func (c *contingency) transpose() *contingency {
+	t := &contingency{
+		counts:   make([]map[int]int, len(c.cols)),
+		rows:     c.cols,
+		cols:     c.rows,
+		n:        c.n,
+		clusters: [2]int{c.clusters[1], c.clusters[0]},
+		entities: [2]int{c.entities[1], c.entities[0]},
+	}
+	for j := range t.counts {
+		t.counts[j] = map[int]int{}
+	}
+	for i, row := range c.counts {
+		for j, v := range row {
+			t.counts[j][i] = v
+		}
+	}
+	return t
+}
+
+// mno returns the minimum number of Move and Join operations transforming
+// the rows into the columns, computed as in Wen and Tzerpos (2004): each
+// row cluster joins the group of the column cluster with which it shares
+// the most entities, moving the others, and ties are broken by a maximum
+// matching, so that as many groups as possible are not empty
+func (c *contingency) mno() int {
+	moves, l := c.n, 0
+	adjacency := make([][]int, len(c.rows))
+	for i, row := range c.counts {
+		if c.rows[i] == 0 {
+			continue
+		}
+		l++
+		max := 0
+		for _, v := range row {
+			if v > max {
+				max = v
+			}
+		}
+		moves -= max
+		for j, v := range row {
+			if v == max {
+				adjacency[i] = append(adjacency[i], j)
+			}
+		}
+	}
+	return moves + l - maxMatching(adjacency, len(c.cols))
+}
+
+// maxMatching returns the size of a maximum matching of a bipartite graph
+// whose left vertices have the given right neighbors, among m
+func maxMatching(adjacency [][]int, m int) int {
+	match := make([]int, m)
+	visited := make([]int, m)
+	for j := range match {
+		match[j] = -1
+	}
+	stamp := 0
+	var augment func(i int) bool
+	augment = func(i int) bool {
+		for _, j := range adjacency[i] {
+			if visited[j] == stamp {
+				continue
+			}
+			visited[j] = stamp
+			if match[j] == -1 || augment(match[j]) {
+				match[j] = i
+				return true
+			}
+		}
+		return false
+	}
+	size := 0
+	for i := range adjacency {
+		stamp++
+		if augment(i) {
+			size++
+		}
+	}
+	return size
+}
+
+// mojoFM returns MoJoFM (Wen and Tzerpos, 2004), the percentage of the
+// largest MoJo distance to the columns that the rows are not, the
+// largest distance being n minus the number of groups left by a
+// decomposition that leaves as few as possible
+func (c *contingency) mojoFM() float64 {
+	var sizes []int
+	for _, s := range c.cols {
+		if s > 0 {
+			sizes = append(sizes, s)
+		}
+	}
+	sort.Ints(sizes)
+	g := 0
+	for _, s := range sizes {
+		if s > g {
+			g++
+		}
+	}
+	max := c.n - g
+	if max == 0 {
+		return 100
+	}
+	return 100 * (1 - float64(c.mno())/float64(max))
+}
+
+// a2a returns the architecture-to-architecture similarity (Le et al.,
+// 2015): the percentage of the operations constructing both decompositions
+// from scratch, each cluster being added and each entity added and moved
+// to its cluster, that transforming one into the other does not take.
+// Entities of only one are removed or added, the others are moved unless
+// their clusters are matched by a maximum weight matching, and the
+// clusters left unmatched are removed or added
+func (c *contingency) a2a() float64 {
+	aco := c.clusters[0] + c.clusters[1] + 2*(c.entities[0]+c.entities[1])
+	if aco == 0 {
+		return 100
+	}
+	mto := c.entities[0] - c.n + c.entities[1] - c.n + c.n - c.maxWeightMatching()
+	if d := c.clusters[0] - c.clusters[1]; d > 0 {
+		mto += d
+	} else {
+		mto -= d
+	}
+	return 100 * (1 - float64(mto)/float64(aco))
+}
+
+// maxWeightMatching returns the largest number of entities shared by the
+// pairs of a matching between rows and columns, matching each connected
+// component of the clusters sharing entities with the Hungarian method
+func (c *contingency) maxWeightMatching() int {
+	r := len(c.rows)
+	parent := make([]int, r+len(c.cols))
+	for v := range parent {
+		parent[v] = v
+	}
+	var find func(v int) int
+	find = func(v int) int {
+		if parent[v] != v {
+			parent[v] = find(parent[v])
+		}
+		return parent[v]
+	}
+	for i, row := range c.counts {
+		for j := range row {
+			parent[find(i)] = find(r + j)
+		}
+	}
+	components := map[int][2][]int{}
+	for v := range parent {
+		if v < r && c.rows[v] == 0 || v >= r && c.cols[v-r] == 0 {
+			continue
+		}
+		k := find(v)
+		p := components[k]
+		if v < r {
+			p[0] = append(p[0], v)
+		} else {
+			p[1] = append(p[1], v-r)
+		}
+		components[k] = p
+	}
+	total := 0
+	for _, p := range components {
+		rows, cols := p[0], p[1]
+		weight := func(i, j int) int { return c.counts[rows[i]][cols[j]] }
+		if len(rows) > len(cols) {
+			rows, cols = cols, rows
+			weight = func(i, j int) int { return c.counts[cols[j]][rows[i]] }
+		}
+		total += hungarian(len(rows), len(cols), weight)
+	}
+	return total
+}
+
+// hungarian returns the largest total weight of a matching of each of n
+// rows to one of m columns, n being at most m, with the Hungarian method
+// in O(n²m)
+func hungarian(n, m int, weight func(i, j int) int) int {
+	u := make([]int, n+1)
+	v := make([]int, m+1)
+	p := make([]int, m+1)
+	way := make([]int, m+1)
+	minv := make([]int, m+1)
+	used := make([]bool, m+1)
+	for i := 1; i <= n; i++ {
+		p[0] = i
+		j0 := 0
+		for j := range minv {
+			minv[j] = math.MaxInt32
+			used[j] = false
+		}
+		for p[j0] != 0 {
+			used[j0] = true
+			i0, delta, j1 := p[j0], math.MaxInt32, 0
+			for j := 1; j <= m; j++ {
+				if used[j] {
+					continue
+				}
+				// weights are negated, so that the cost is minimized
+				if cur := -weight(i0-1, j-1) - u[i0] - v[j]; cur < minv[j] {
+					minv[j], way[j] = cur, j0
+				}
+				if minv[j] < delta {
+					delta, j1 = minv[j], j
+				}
+			}
+			for j := 0; j <= m; j++ {
+				if used[j] {
+					u[p[j]] += delta
+					v[j] -= delta
+				} else {
+					minv[j] -= delta
+				}
+			}
+			j0 = j1
+		}
+		for j0 != 0 {
+			j1 := way[j0]
+			p[j0] = p[j1]
+			j0 = j1
+		}
+	}
+	total := 0
+	for j := 1; j <= m; j++ {
+		if p[j] != 0 {
+			total += weight(p[j]-1, j-1)
+		}
+	}
+	return total
+}
+
+// ari returns the Adjusted Rand Index (Hubert and Arabie, 1985), 1 for
+// equal decompositions and about 0 for independent ones
+func (c *contingency) ari() float64 {
+	pairs := func(k int) float64 { return float64(k) * float64(k-1) / 2 }
+	var index, rows, cols float64
+	for _, row := range c.counts {
+		for _, v := range row {
+			index += pairs(v)
+		}
+	}
+	for _, s := range c.rows {
+		rows += pairs(s)
+	}
+	for _, s := range c.cols {
+		cols += pairs(s)
+	}
+	if c.n < 2 {
+		return 1
+	}
+	expected := rows * cols / pairs(c.n)
+	max := (rows + cols) / 2
+	if max == expected {
+		return 1
+	}
+	return (index - expected) / (max - expected)
+}
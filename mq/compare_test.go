@@ -0,0 +1,50 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	for _, test := range []struct {
+		a, b   decomposition
+		mojo   int
+		mojoFM float64
+		ari    float64
+	}{
+		// moving c and d out of A takes two moves, while joining the
+		// clusters of B takes one
+		{
+			decomposition{"a": "cluster0", "b": "cluster0", "c": "cluster0", "d": "cluster0"},
+			decomposition{"a": "cluster0", "b": "cluster0", "c": "cluster1", "d": "cluster1"},
+			1, 0, 0,
+		},
+		{
+			decomposition{"a": "cluster0", "b": "cluster0", "c": "cluster1", "d": "cluster2"},
+			decomposition{"a": "cluster1", "b": "cluster1", "c": "cluster0", "d": "cluster0"},
+			1, 50, 4.0 / 7,
+		},
+		{
+			decomposition{"a": "cluster0", "b": "cluster1"},
+			decomposition{"a": "cluster2", "b": "cluster3"},
+			0, 100, 1,
+		},
+	} {
+		r := compareDecompositions(test.a, test.b)
+		if r.MoJo != test.mojo || math.Abs(r.MoJoFM-test.mojoFM) > 1e-9 || math.Abs(r.ARI-test.ari) > 1e-9 {
+			t.Errorf("%v and %v: expected mojo %v, mojofm %v and ari %v, got %+v",
+				test.a, test.b, test.mojo, test.mojoFM, test.ari, r)
+		}
+	}
+}
+
+func TestA2a(t *testing.T) {
+	a := decomposition{"a": "cluster0", "b": "cluster0", "c": "cluster1", "x": "cluster1"}
+	b := decomposition{"a": "cluster5", "b": "cluster6", "c": "cluster6", "y": "cluster7"}
+	// removing x, adding y, moving one of a, b and c, and adding a cluster
+	// take 4 operations, and constructing both 2+8 and 3+8
+	r := compareDecompositions(a, b)
+	if want := 100 * (1 - 4.0/21); math.Abs(r.A2a-want) > 1e-9 || r.OnlyFirst != 1 || r.OnlySecond != 1 {
+		t.Errorf("expected a2a %v, got %+v", want, r)
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/awalterschulze/gographviz"
+)
+
+// decomposition is the cluster of each entity of a DOT file, whose
+// clusters are the subgraphs named cluster*. Entities of nested clusters
+// belong to the innermost one
+type decomposition map[string]string
+
+func readDecomposition(r io.Reader) (decomposition, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	gast, err := gographviz.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	g := gographviz.NewGraph()
+	if err := gographviz.Analyse(gast, g); err != nil {
+		return nil, err
+	}
+	d := decomposition{}
+	for cluster, nodes := range g.Relations.ParentToChildren {
+		if !strings.HasPrefix(cluster, "cluster") {
+			continue
+		}
+		for node := range nodes {
+			if !g.IsSubGraph(node) {
+				d[strings.ReplaceAll(node, `"`, "")] = cluster
+			}
+		}
+	}
+	return d, nil
+}
+
+func readDecompositionFile(name string) (decomposition, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readDecomposition(f)
+}
+
+// clusters returns the names of the clusters with entities, sorted by
+// lessCluster
+func (d decomposition) clusters() []string {
+	seen := map[string]bool{}
+	var result []string
+	for _, c := range d {
+		if !seen[c] {
+			seen[c] = true
+			result = append(result, c)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return lessCluster(result[i], result[j]) })
+	return result
+}
+
+// lessCluster orders cluster names by length and then alphabetically, so
+// that cluster2 precedes cluster10
+func lessCluster(a, b string) bool {
+	return len(a) < len(b) || len(a) == len(b) && a < b
+}
@@ -1,65 +1,48 @@
 package main
 
 import (
+	"flag"
 	"fmt"
-	"io/ioutil"
 	"log"
 	"os"
-	"strings"
-
-	"github.com/awalterschulze/gographviz"
-	scanner "github.com/project-draco/pkg/dependency-scanner"
 )
 
 func main() {
-	data, err := ioutil.ReadAll(os.Stdin)
-	check(err)
-	gast, err := gographviz.Parse(data)
-	check(err)
-	g := gographviz.NewGraph()
-	err = gographviz.Analyse(gast, g)
-	check(err)
-	clusterOfNode := map[string]string{}
-	for cluster, nodes := range g.Relations.ParentToChildren {
-		if !strings.HasPrefix(cluster, "cluster") {
-			continue
-		}
-		for node := range nodes {
-			clusterOfNode[strings.ReplaceAll(node, `"`, "")] = cluster
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "compare", "mojo", "mojofm", "a2a", "ari":
+			compare(os.Args[1], os.Args[2:])
+			return
 		}
 	}
-	f, err := os.Open(os.Args[1])
-	check(err)
-	α := make(map[string]float64)
-	β := make(map[string]float64)
-	scanner := scanner.NewDependencyScanner(f)
-	for scanner.Scan() {
-		dep := scanner.Dependency()
-		i := clusterOfNode[dep.From[0]]
-		j := clusterOfNode[dep.To]
-		if i == "" || j == "" {
-			continue
-		}
-		fmt.Println(i, j)
-		if i == j {
-			α[i] += float64(dep.SupportCount)
-		} else {
-			β[i] += float64(dep.SupportCount)
-			β[j] += float64(dep.SupportCount)
-		}
+	format := flag.String("format", "value", "value|table|json: Turbo MQ only, or a report per cluster with modularity, EVM and coverage")
+	top := flag.Int("top", 3, "partner clusters of each cluster in the report")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: mq [options] <mdg file> < <dot file>\n       mq compare|mojo|mojofm|a2a|ari [options] <dot file> <reference dot file>")
+		flag.PrintDefaults()
 	}
-	check(scanner.Err())
-	mq := 0.0
-	for cluster := range g.Relations.ParentToChildren {
-		if !strings.HasPrefix(cluster, "cluster") {
-			continue
-		}
-		if α[cluster] == 0 && β[cluster] == 0 {
-			continue
-		}
-		mq += 2 * α[cluster] / (2*α[cluster] + β[cluster])
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	d, err := readDecomposition(os.Stdin)
+	check(err)
+	f, err := os.Open(flag.Arg(0))
+	check(err)
+	defer f.Close()
+	rep, err := newReport(d, f, *top)
+	check(err)
+	switch *format {
+	case "value":
+		fmt.Println(rep.MQ)
+	case "table":
+		check(rep.writeTable(os.Stdout))
+	case "json":
+		check(rep.writeJSON(os.Stdout))
+	default:
+		log.Fatalf("invalid format: %v", *format)
 	}
-	fmt.Println(mq)
 }
 
 func check(err error) {
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	scanner "github.com/project-draco/pkg/dependency-scanner"
+)
+
+// report is the quality of a decomposition in a MDG, overall and per
+// cluster. Only the dependencies between entities of the decomposition
+// are taken, weighted by their support counts
+type report struct {
+	MQ float64 `json:"mq"`
+	// Modularity is Newman's modularity, taking dependencies as undirected
+	Modularity float64 `json:"modularity"`
+	// EVM (Tucker et al., 2001) adds 1 for each pair of entities of a
+	// cluster with a dependency between them and subtracts 1 for each pair
+	// without one
+	EVM int `json:"evm"`
+	// Coverage is the fraction of the weight of the dependencies inside
+	// clusters
+	Coverage float64         `json:"coverage"`
+	Clusters []clusterReport `json:"clusters"`
+}
+
+// clusterReport is the quality of a cluster, with its intra-cluster (α)
+// and inter-cluster (β) weights, its contribution to Turbo MQ, and the
+// clusters with which it has the largest weights of dependencies
+type clusterReport struct {
+	Name     string    `json:"name"`
+	Size     int       `json:"size"`
+	Alpha    float64   `json:"alpha"`
+	Beta     float64   `json:"beta"`
+	MQ       float64   `json:"mq"`
+	Partners []partner `json:"partners"`
+}
+
+type partner struct {
+	Cluster string  `json:"cluster"`
+	Weight  float64 `json:"weight"`
+}
+
+// newReport reads the MDG and reports the quality of the decomposition,
+// with the top partners of each cluster
+func newReport(d decomposition, r io.Reader, top int) (*report, error) {
+	α := map[string]float64{}
+	β := map[string]float64{}
+	degree := map[string]float64{}
+	between := map[string]map[string]float64{}
+	linked := map[[2]string]bool{}
+	m := 0.0
+	scanner := scanner.NewDependencyScanner(r)
+	for scanner.Scan() {
+		dep := scanner.Dependency()
+		i := d[dep.From[0]]
+		j := d[dep.To]
+		if i == "" || j == "" {
+			continue
+		}
+		w := float64(dep.SupportCount)
+		m += w
+		degree[i] += w
+		degree[j] += w
+		if i == j {
+			α[i] += w
+			if dep.From[0] != dep.To {
+				pair := [2]string{dep.From[0], dep.To}
+				if pair[0] > pair[1] {
+					pair[0], pair[1] = pair[1], pair[0]
+				}
+				linked[pair] = true
+			}
+			continue
+		}
+		β[i] += w
+		β[j] += w
+		for _, p := range [][2]string{{i, j}, {j, i}} {
+			if between[p[0]] == nil {
+				between[p[0]] = map[string]float64{}
+			}
+			between[p[0]][p[1]] += w
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	size := map[string]int{}
+	for _, c := range d {
+		size[c]++
+	}
+	links := map[string]int{}
+	for pair := range linked {
+		links[d[pair[0]]]++
+	}
+	rep := &report{}
+	inside := 0.0
+	for _, c := range d.clusters() {
+		cr := clusterReport{Name: c, Size: size[c], Alpha: α[c], Beta: β[c]}
+		if α[c] != 0 || β[c] != 0 {
+			cr.MQ = 2 * α[c] / (2*α[c] + β[c])
+		}
+		rep.MQ += cr.MQ
+		if m > 0 {
+			rep.Modularity += α[c]/m - (degree[c]/(2*m))*(degree[c]/(2*m))
+		}
+		inside += α[c]
+		rep.EVM += 2*links[c] - size[c]*(size[c]-1)/2
+		for other, w := range between[c] {
+			cr.Partners = append(cr.Partners, partner{other, w})
+		}
+		sort.Slice(cr.Partners, func(i, j int) bool {
+			p, q := cr.Partners[i], cr.Partners[j]
+			return p.Weight > q.Weight || p.Weight == q.Weight && lessCluster(p.Cluster, q.Cluster)
+		})
+		if len(cr.Partners) > top {
+			cr.Partners = cr.Partners[:top]
+		}
+		rep.Clusters = append(rep.Clusters, cr)
+	}
+	if m > 0 {
+		rep.Coverage = inside / m
+	}
+	return rep, nil
+}
+
+// writeTable writes a line per cluster, followed by the overall metrics
+func (rep *report) writeTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "cluster\tsize\tα\tβ\tmq\tpartners")
+	for _, c := range rep.Clusters {
+		var partners []string
+		for _, p := range c.Partners {
+			partners = append(partners, fmt.Sprintf("%v (%v)", p.Cluster, p.Weight))
+		}
+		fmt.Fprintf(tw, "%v\t%v\t%v\t%v\t%.4f\t%v\n", c.Name, c.Size, c.Alpha, c.Beta, c.MQ, strings.Join(partners, ", "))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "\nmq\t%v\nmodularity\t%v\nevm\t%v\ncoverage\t%v\n", rep.MQ, rep.Modularity, rep.EVM, rep.Coverage)
+	return err
+}
+
+func (rep *report) writeJSON(w io.Writer) error {
+	data, err := json.MarshalIndent(rep, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
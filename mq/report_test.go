@@ -0,0 +1,29 @@
+package main
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReport(t *testing.T) {
+	d := decomposition{"a": "cluster0", "b": "cluster0", "c": "cluster1", "d": "cluster1", "e": "cluster1"}
+	rep, err := newReport(d, strings.NewReader("a\tb\t2\nc\td\t1\nb\tc\t1\nx\ta\t5\n"), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []clusterReport{
+		{"cluster0", 2, 2, 1, 0.8, []partner{{"cluster1", 1}}},
+		{"cluster1", 3, 1, 1, 2.0 / 3, []partner{{"cluster0", 1}}},
+	}
+	if !reflect.DeepEqual(rep.Clusters, want) {
+		t.Errorf("expected clusters %+v, got %+v", want, rep.Clusters)
+	}
+	// the degrees of the clusters are 5 and 3, of a total weight of 4
+	modularity := 2.0/4 - (5.0/8)*(5.0/8) + 1.0/4 - (3.0/8)*(3.0/8)
+	if math.Abs(rep.MQ-(0.8+2.0/3)) > 1e-9 || math.Abs(rep.Modularity-modularity) > 1e-9 ||
+		rep.EVM != 0 || rep.Coverage != 0.75 {
+		t.Errorf("unexpected report %+v", rep)
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// backboneStats tells how much of the graph the disparity filter retained
+type backboneStats struct {
+	alpha                  float64
+	edges, keptEdges       int
+	vertices, keptVertices int
+	weight, keptWeight     int
+}
+
+func (s backboneStats) String() string {
+	ratio := func(kept, total int) string {
+		if total == 0 {
+			return fmt.Sprintf("%v/%v", kept, total)
+		}
+		return fmt.Sprintf("%v/%v (%.1f%%)", kept, total, 100*float64(kept)/float64(total))
+	}
+	return fmt.Sprintf("backbone (alpha=%v): edges: %v, vertices: %v, weight: %v",
+		s.alpha, ratio(s.keptEdges, s.edges), ratio(s.keptVertices, s.vertices),
+		ratio(s.keptWeight, s.weight))
+}
+
+// disparity extracts the backbone of the graph according to the disparity
+// filter (Serrano, Boguñá and Vespignani, 2009). The graph is taken as
+// undirected, the weight of a pair of entities being the largest support
+// of its edges. A pair is kept if its weight is significant at level alpha
+// for any of its entities, i.e., if it is unlikely under the null
+// hypothesis that the entity's strength is distributed uniformly at random
+// among its k partners: (1 - w/s)^(k-1) < alpha. An entity with a single
+// partner cannot tell, so a pair of such entities is always kept. The
+// directed edges of the kept pairs are returned in their original order
+func disparity(edges []edge, alpha float64) ([]edge, backboneStats) {
+	type pair [2]string
+	key := func(e edge) pair {
+		if e.source < e.destination {
+			return pair{e.source, e.destination}
+		}
+		return pair{e.destination, e.source}
+	}
+	weight := map[pair]int{}
+	vertices := map[string]bool{}
+	for _, e := range edges {
+		vertices[e.source] = true
+		vertices[e.destination] = true
+		if e.source == e.destination {
+			continue
+		}
+		if k := key(e); e.support > weight[k] {
+			weight[k] = e.support
+		}
+	}
+	strength := map[string]int{}
+	degree := map[string]int{}
+	for p, w := range weight {
+		for _, v := range p {
+			strength[v] += w
+			degree[v]++
+		}
+	}
+	significant := func(v string, w int) bool {
+		k := degree[v]
+		if k < 2 {
+			return false
+		}
+		return math.Pow(1-float64(w)/float64(strength[v]), float64(k-1)) < alpha
+	}
+	kept := map[pair]bool{}
+	for p, w := range weight {
+		if significant(p[0], w) || significant(p[1], w) || (degree[p[0]] == 1 && degree[p[1]] == 1) {
+			kept[p] = true
+		}
+	}
+
+	stats := backboneStats{alpha: alpha, edges: len(edges), vertices: len(vertices)}
+	keptVertices := map[string]bool{}
+	var result []edge
+	for _, e := range edges {
+		stats.weight += e.support
+		if e.source == e.destination || !kept[key(e)] {
+			continue
+		}
+		result = append(result, e)
+		keptVertices[e.source] = true
+		keptVertices[e.destination] = true
+		stats.keptWeight += e.support
+	}
+	stats.keptEdges = len(result)
+	stats.keptVertices = len(keptVertices)
+	return result, stats
+}
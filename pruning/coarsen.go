@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/project-draco/tools/historage"
+)
+
+// newModule returns a function mapping an entity to the module it belongs
+// to at the given level, or to an empty string if the entity cannot be
+// mapped. A regular expression defines modules as its first submatch, or
+// as the whole match if it has no groups
+func newModule(level, expr string) (func(string) string, error) {
+	switch level {
+	case "class":
+		return historage.Class, nil
+	case "file":
+		return historage.File, nil
+	case "package":
+		return historage.Package, nil
+	case "regex":
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, err
+		}
+		return func(name string) string {
+			match := re.FindStringSubmatch(name)
+			if len(match) == 0 {
+				return ""
+			}
+			if re.NumSubexp() > 0 {
+				return match[1]
+			}
+			return match[0]
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown level %v", level)
+}
+
+// coarsen maps the entities of the MDG to modules, dropping edges inside a
+// module or between entities without a module. If the MDG has the commits
+// of each edge, supports and counts are the numbers of distinct commits,
+// otherwise they are the sums of the supports and counts of the entities
+func (m *mdg) coarsen(module func(string) string, counts map[string]int) ([]edge, map[string]int) {
+	var edges []edge
+	index := map[[2]string]int{}
+	commitsByEdge := map[[2]string]map[string]bool{}
+	for _, e := range m.edges {
+		key := [2]string{module(e.source), module(e.destination)}
+		if key[0] == "" || key[1] == "" || key[0] == key[1] {
+			continue
+		}
+		i, ok := index[key]
+		if !ok {
+			i = len(edges)
+			index[key] = i
+			edges = append(edges, edge{key[0], key[1], 0})
+		}
+		commits := m.commitsByEdge[[2]string{e.source, e.destination}]
+		if commits == nil {
+			edges[i].support += e.support
+			continue
+		}
+		if commitsByEdge[key] == nil {
+			commitsByEdge[key] = map[string]bool{}
+		}
+		for hash := range commits {
+			commitsByEdge[key][hash] = true
+		}
+		edges[i].support = len(commitsByEdge[key])
+	}
+
+	moduleCounts := map[string]int{}
+	if len(m.commitsByEntity) > 0 {
+		commitsByModule := map[string]map[string]bool{}
+		for name, commits := range m.commitsByEntity {
+			mod := module(name)
+			if mod == "" {
+				continue
+			}
+			if commitsByModule[mod] == nil {
+				commitsByModule[mod] = map[string]bool{}
+			}
+			for hash := range commits {
+				commitsByModule[mod][hash] = true
+			}
+		}
+		for mod, commits := range commitsByModule {
+			moduleCounts[mod] = len(commits)
+		}
+		return edges, moduleCounts
+	}
+	for name, c := range counts {
+		if mod := module(name); mod != "" {
+			moduleCounts[mod] += c
+		}
+	}
+	return edges, moduleCounts
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/project-draco/tools/historage"
+)
+
+func TestModules(t *testing.T) {
+	name := "src_p_A.java/[CN]/A/[CN]/B/[MT]/m()"
+	for _, test := range []struct {
+		level, expr, want string
+	}{
+		{"class", "", "src_p_A.java/[CN]/A/[CN]/B"},
+		{"file", "", "src_p_A.java"},
+		{"package", "", "src_p"},
+		{"regex", `^src_(\w+)_`, "p"},
+		{"regex", `\[CN\]/\w+`, "[CN]/A"},
+		{"regex", `^src_(\w+)_(\w+)\.java`, "p"},
+		{"regex", `^test`, ""},
+	} {
+		module, err := newModule(test.level, test.expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := module(name); got != test.want {
+			t.Errorf("%v %v: expected %v but was %v", test.level, test.expr, test.want, got)
+		}
+	}
+}
+
+func TestCoarsen(t *testing.T) {
+	mdg := "p_A.java/[CN]/A/[FE]/a\tp_B.java/[CN]/B/[FE]/b\t2\t1\t2\t3\tc1,c2\n" +
+		"p_A.java/[CN]/A/[FE]/a2\tp_B.java/[CN]/B/[FE]/b\t2\t1\t2\t3\tc2,c3\n" +
+		"p_A.java/[CN]/A/[FE]/a\tp_A.java/[CN]/A/[FE]/a2\t1\t1\t2\t3\tc2\n"
+	for _, test := range []struct {
+		name       string
+		mdg        string
+		wantEdges  []edge
+		wantCounts map[string]int
+	}{
+		{
+			"distinct commits",
+			mdg,
+			[]edge{{"p_A.java", "p_B.java", 3}},
+			map[string]int{"p_A.java": 3, "p_B.java": 3},
+		},
+		{
+			"sums",
+			regexp.MustCompile(`\t[c0-9,]+\n`).ReplaceAllString(mdg, "\n"),
+			[]edge{{"p_A.java", "p_B.java", 4}},
+			map[string]int{"p_A.java": 4, "p_B.java": 2},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			m, err := readMDG(strings.NewReader(test.mdg), normalization{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			counts := map[string]int{"p_A.java/[CN]/A/[FE]/a": 2, "p_A.java/[CN]/A/[FE]/a2": 2, "p_B.java/[CN]/B/[FE]/b": 2}
+			edges, moduleCounts := m.coarsen(historage.File, counts)
+			if !reflect.DeepEqual(edges, test.wantEdges) || !reflect.DeepEqual(moduleCounts, test.wantCounts) {
+				t.Errorf("Got %v %v want %v %v", edges, moduleCounts, test.wantEdges, test.wantCounts)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// filters holds the criteria an edge must meet to be kept. Absolute and
+// percentile thresholds are applied first, then the disparity filter if
+// alpha is positive, and then topK keeps, for each source entity, the
+// edges with the k highest values of the rank metric
+type filters struct {
+	minSupport           int
+	minConfidence        float64
+	minLift              float64
+	minJaccard           float64
+	supportPercentile    float64
+	confidencePercentile float64
+	alpha                float64
+	topK                 int
+	rank                 string
+}
+
+func (g *graph) confidence(e edge) float64 {
+	return float64(e.support) / float64(g.counts[e.source])
+}
+
+// lift is the ratio between the observed support and the support expected
+// if the entities changed independently
+func (g *graph) lift(e edge) float64 {
+	return float64(e.support) * float64(g.commits) /
+		(float64(g.counts[e.source]) * float64(g.counts[e.destination]))
+}
+
+// jaccard is the number of commits changing both entities divided by the
+// number of commits changing any of them
+func (g *graph) jaccard(e edge) float64 {
+	return float64(e.support) /
+		float64(g.counts[e.source]+g.counts[e.destination]-e.support)
+}
+
+func (g *graph) metric(name string) (func(edge) float64, error) {
+	switch name {
+	case "support":
+		return func(e edge) float64 { return float64(e.support) }, nil
+	case "confidence":
+		return g.confidence, nil
+	case "lift":
+		if g.commits == 0 {
+			return nil, fmt.Errorf("lift requires the number of commits")
+		}
+		return g.lift, nil
+	case "jaccard":
+		return g.jaccard, nil
+	}
+	return nil, fmt.Errorf("unknown metric %v", name)
+}
+
+// percentile returns the smallest value such that p percent of the values
+// are less than or equal to it (nearest rank)
+func percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+	i := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(sorted) {
+		i = len(sorted) - 1
+	}
+	return sorted[i]
+}
+
+// prune returns the edges of the graph that meet the filters
+func (g *graph) prune(f filters) ([]edge, error) {
+	type criterion struct {
+		metric func(edge) float64
+		min    float64
+	}
+	var criteria []criterion
+	add := func(name string, min float64, percent float64) error {
+		if min == 0 && percent == 0 {
+			return nil
+		}
+		m, err := g.metric(name)
+		if err != nil {
+			return err
+		}
+		criteria = append(criteria, criterion{m, min})
+		if percent == 0 {
+			return nil
+		}
+		values := make([]float64, len(g.edges))
+		for i, e := range g.edges {
+			values[i] = m(e)
+		}
+		criteria = append(criteria, criterion{m, percentile(values, percent)})
+		return nil
+	}
+	if err := add("support", float64(f.minSupport), f.supportPercentile); err != nil {
+		return nil, err
+	}
+	if err := add("confidence", f.minConfidence, f.confidencePercentile); err != nil {
+		return nil, err
+	}
+	if err := add("lift", f.minLift, 0); err != nil {
+		return nil, err
+	}
+	if err := add("jaccard", f.minJaccard, 0); err != nil {
+		return nil, err
+	}
+	var result []edge
+	for _, e := range g.edges {
+		keep := true
+		for _, c := range criteria {
+			if c.metric(e) < c.min {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			result = append(result, e)
+		}
+	}
+	if f.alpha > 0 {
+		var stats backboneStats
+		result, stats = disparity(result, f.alpha)
+		g.backbone = &stats
+	}
+	if f.topK <= 0 {
+		return result, nil
+	}
+	rank, err := g.metric(f.rank)
+	if err != nil {
+		return nil, err
+	}
+	return topK(result, f.topK, rank), nil
+}
+
+// topK keeps, for each source entity, its k edges with the highest rank,
+// along with the edges tied with the k-th one, preserving the edges order
+func topK(edges []edge, k int, rank func(edge) float64) []edge {
+	values := map[string][]float64{}
+	for _, e := range edges {
+		values[e.source] = append(values[e.source], rank(e))
+	}
+	threshold := map[string]float64{}
+	for source, v := range values {
+		sort.Sort(sort.Reverse(sort.Float64Slice(v)))
+		if len(v) > k {
+			threshold[source] = v[k-1]
+		} else {
+			threshold[source] = math.Inf(-1)
+		}
+	}
+	var result []edge
+	for _, e := range edges {
+		if rank(e) >= threshold[e.source] {
+			result = append(result, e)
+		}
+	}
+	return result
+}
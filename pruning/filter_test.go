@@ -0,0 +1,110 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPrune(t *testing.T) {
+	g := &graph{
+		edges: []edge{
+			{"a", "b", 4},
+			{"a", "c", 2},
+			{"a", "d", 2},
+			{"b", "a", 4},
+			{"c", "d", 1},
+		},
+		counts:  map[string]int{"a": 8, "b": 4, "c": 2, "d": 4},
+		commits: 16,
+	}
+	tests := []struct {
+		name string
+		f    filters
+		want string
+	}{
+		{"minimum support", filters{minSupport: 2}, "a>b a>c a>d b>a"},
+		{"minimum confidence", filters{minConfidence: 0.5}, "a>b b>a c>d"},
+		{"minimum lift", filters{minLift: 2}, "a>b a>c b>a c>d"},
+		{"minimum jaccard", filters{minJaccard: 0.5}, "a>b b>a"},
+		{"support percentile", filters{supportPercentile: 60}, "a>b a>c a>d b>a"},
+		{"top 1 by support", filters{topK: 1, rank: "support"}, "a>b b>a c>d"},
+		{"top 2 with ties", filters{topK: 2, rank: "support"}, "a>b a>c a>d b>a c>d"},
+		{"top 1 by lift", filters{topK: 1, rank: "lift"}, "a>b a>c b>a c>d"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pruned, err := g.prune(test.f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, e := range pruned {
+				got = append(got, e.source+">"+e.destination)
+			}
+			if strings.Join(got, " ") != test.want {
+				t.Errorf("Got %v want %v", strings.Join(got, " "), test.want)
+			}
+		})
+	}
+	g.commits = 0
+	if _, err := g.prune(filters{minLift: 1}); err == nil {
+		t.Error("Expected error computing lift without the number of commits")
+	}
+}
+
+func TestReadMDG(t *testing.T) {
+	mdg := "# co-change head=1\n" +
+		"A/[MT]/m()/body\tB\t2\t0.5\t4\t10\tc1,c2\n" +
+		"A/[MT]/m()/parameters\tB\t1\t0.5\t2\t10\tc2\n" +
+		"B\tA/[MT]/m()/body\t2\t0.5\t4\t10\tc1,c2\n" +
+		"A/package\tB\t1\t0.5\t2\t10\tc3\n"
+	m, err := readMDG(strings.NewReader(mdg), normalization{join: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []edge{{"A/[MT]/m()", "B", 3}, {"B", "A/[MT]/m()", 2}}
+	if !reflect.DeepEqual(m.edges, want) || m.commits != 10 || m.header != "# co-change head=1" {
+		t.Errorf("Got %v %v %v", m.edges, m.commits, m.header)
+	}
+	// the commits of joined entities are not counted twice
+	counts, commits := m.countsFromCommits()
+	if !reflect.DeepEqual(counts, map[string]int{"A/[MT]/m()": 2, "B": 2}) || commits != 2 {
+		t.Errorf("Got %v %v", counts, commits)
+	}
+	counts = map[string]int{"A/[MT]/m()/body": 4, "A/[MT]/m()/parameters": 2, "A/package": 2, "B": 4}
+	if err := m.validate(counts, "# co-change head=1", 10); err != nil {
+		t.Error(err)
+	}
+	counts["B"] = 3
+	if err := m.validate(counts, "# co-change head=1", 10); err == nil {
+		t.Error("Expected error validating inconsistent counts")
+	}
+	if err := m.validate(counts, "# co-change head=2", 0); err == nil {
+		t.Error("Expected error validating counts from another run")
+	}
+	if _, err := readMDG(strings.NewReader("a\tb\n"), normalization{}); err == nil {
+		t.Error("Expected error reading an edge without support")
+	}
+}
+
+func TestDisparity(t *testing.T) {
+	edges := []edge{
+		{"h", "a", 10}, {"a", "h", 10},
+		{"h", "b", 1}, {"b", "h", 1},
+		{"h", "c", 1},
+		{"h", "d", 1},
+		{"x", "y", 1},
+	}
+	backbone, stats := disparity(edges, 0.05)
+	want := []edge{{"h", "a", 10}, {"a", "h", 10}, {"x", "y", 1}}
+	if !reflect.DeepEqual(backbone, want) {
+		t.Errorf("Got %v want %v", backbone, want)
+	}
+	wantStats := backboneStats{
+		alpha: 0.05, edges: 7, keptEdges: 3, vertices: 7, keptVertices: 4, weight: 25, keptWeight: 21,
+	}
+	if stats != wantStats {
+		t.Errorf("Got %+v want %+v", stats, wantStats)
+	}
+}
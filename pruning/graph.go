@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	joinre1 = regexp.MustCompile("/body$")
+	joinre2 = regexp.MustCompile("/parameters$")
+	joinre3 = regexp.MustCompile("/package$")
+)
+
+type edge struct {
+	source, destination string
+	support             int
+}
+
+// graph is a co-change MDG along with the number of commits of each entity
+type graph struct {
+	edges  []edge
+	counts map[string]int
+	// commits is the number of commits mined, or zero if unknown
+	commits int
+	// backbone is set when the disparity filter is applied
+	backbone *backboneStats
+}
+
+// normalization tells how entity names are normalized, so that the
+// counts and the MDG refer to the same entities
+type normalization struct {
+	join, ignoreParameters bool
+}
+
+// normalize returns the normalized name of an entity, or false if the
+// entity must be ignored
+func (n normalization) normalize(name string) (string, bool) {
+	if n.ignoreParameters && joinre2.MatchString(name) {
+		return "", false
+	}
+	if n.join {
+		if joinre3.MatchString(name) {
+			return "", false
+		}
+		name = joinre1.ReplaceAllLiteralString(name, "")
+		name = joinre2.ReplaceAllLiteralString(name, "")
+	}
+	return name, true
+}
+
+// mdg is what a MDG tells besides its edges. co-change writes, after the
+// support count, the confidence, the antecedent count, the number of
+// commits mined and, in the rules-and-commits output, the commits of
+// each rule
+type mdg struct {
+	edges []edge
+	// header is the line starting with # written by co-change -header
+	header string
+	// antecedentCounts maps each antecedent, as written, to its count
+	antecedentCounts map[string]int
+	// commits is the number of commits mined, or zero if unknown
+	commits int
+	// commitsByEntity maps each normalized entity to the commits of its edges
+	commitsByEntity map[string]map[string]bool
+	// commitsByEdge maps each normalized edge to its commits
+	commitsByEdge map[[2]string]map[string]bool
+}
+
+// readCounts reads a count file, written by co-change -output count,
+// returning the counts of the entities as written
+func readCounts(r io.Reader) (map[string]int, string, error) {
+	counts := map[string]int{}
+	header := ""
+	cs := bufio.NewScanner(r)
+	cs.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for cs.Scan() {
+		if strings.HasPrefix(cs.Text(), "#") {
+			header = cs.Text()
+			continue
+		}
+		arr := strings.Split(cs.Text(), "\t")
+		if len(arr) < 2 {
+			return nil, "", fmt.Errorf("invalid count line: %q", cs.Text())
+		}
+		c, err := strconv.Atoi(arr[1])
+		if err != nil {
+			return nil, "", err
+		}
+		counts[arr[0]] = c
+	}
+	return counts, header, cs.Err()
+}
+
+// normalizeCounts sums the counts of the entities with the same
+// normalized name
+func normalizeCounts(counts map[string]int, n normalization) map[string]int {
+	result := map[string]int{}
+	for name, c := range counts {
+		if name, ok := n.normalize(name); ok {
+			result[name] += c
+		}
+	}
+	return result
+}
+
+// readMDG reads a MDG whose lines have at least the source, the
+// destination and the support count. Edges joined by the normalization
+// have their supports summed
+func readMDG(r io.Reader, n normalization) (*mdg, error) {
+	m := &mdg{
+		antecedentCounts: map[string]int{},
+		commitsByEntity:  map[string]map[string]bool{},
+		commitsByEdge:    map[[2]string]map[string]bool{},
+	}
+	index := map[[2]string]int{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.HasPrefix(scanner.Text(), "#") {
+			m.header = scanner.Text()
+			continue
+		}
+		arr := strings.Split(scanner.Text(), "\t")
+		if len(arr) < 3 {
+			return nil, fmt.Errorf("line %v: expected at least 3 columns", line)
+		}
+		c, err := strconv.Atoi(arr[2])
+		if err != nil {
+			return nil, fmt.Errorf("line %v: %v", line, err)
+		}
+		if len(arr) >= 6 {
+			count, err1 := strconv.Atoi(arr[4])
+			total, err2 := strconv.Atoi(arr[5])
+			if err1 != nil || err2 != nil {
+				return nil, fmt.Errorf("line %v: invalid counts", line)
+			}
+			m.antecedentCounts[arr[0]] = count
+			if total > m.commits {
+				m.commits = total
+			}
+		}
+		source, ok1 := n.normalize(arr[0])
+		destination, ok2 := n.normalize(arr[1])
+		if !ok1 || !ok2 {
+			continue
+		}
+		if len(arr) >= 7 {
+			key := [2]string{source, destination}
+			if m.commitsByEdge[key] == nil {
+				m.commitsByEdge[key] = map[string]bool{}
+			}
+			for _, name := range key {
+				if m.commitsByEntity[name] == nil {
+					m.commitsByEntity[name] = map[string]bool{}
+				}
+			}
+			for _, hash := range strings.Split(arr[6], ",") {
+				m.commitsByEdge[key][hash] = true
+				m.commitsByEntity[source][hash] = true
+				m.commitsByEntity[destination][hash] = true
+			}
+		}
+		if i, ok := index[[2]string{source, destination}]; ok && n.join {
+			m.edges[i].support += c
+			continue
+		}
+		index[[2]string{source, destination}] = len(m.edges)
+		m.edges = append(m.edges, edge{source, destination, c})
+	}
+	return m, scanner.Err()
+}
+
+// countsFromCommits returns the number of distinct commits of each entity
+// and of all entities, which are exact if co-change did not filter any
+// rule out
+func (m *mdg) countsFromCommits() (map[string]int, int) {
+	counts := map[string]int{}
+	all := map[string]bool{}
+	for name, commits := range m.commitsByEntity {
+		counts[name] = len(commits)
+		for hash := range commits {
+			all[hash] = true
+		}
+	}
+	return counts, len(all)
+}
+
+// validate checks that counts read elsewhere agree with the antecedent
+// counts and the number of commits written in the MDG
+func (m *mdg) validate(counts map[string]int, header string, commits int) error {
+	if header != "" && m.header != "" && header != m.header {
+		return fmt.Errorf("counts and MDG come from different runs:\n%v\n%v", header, m.header)
+	}
+	if commits > 0 && m.commits > 0 && commits != m.commits {
+		return fmt.Errorf("counts of %v commits, but MDG of %v commits", commits, m.commits)
+	}
+	for name, c := range m.antecedentCounts {
+		if counts[name] != c {
+			return fmt.Errorf("count of %v is %v, but it is %v in the MDG", name, counts[name], c)
+		}
+	}
+	return nil
+}
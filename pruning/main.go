@@ -1,117 +1,233 @@
 package main
 
 import (
-	"bufio"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
 	"os"
-	"regexp"
 	"strconv"
 	"strings"
+
+	"github.com/project-draco/tools/mining/co-change/history"
 )
 
 func main() {
 	pminsupport := flag.Int("minsupport", 1, "Minimum support count")
 	pminconfidence := flag.Float64("minconfidence", 0.0, "Minimum confidence")
+	pminlift := flag.Float64("minlift", 0.0, "Minimum lift (requires the number of commits)")
+	pminjaccard := flag.Float64("minjaccard", 0.0, "Minimum Jaccard similarity")
+	psupportpercentile := flag.Float64("supportpercentile", 0.0,
+		"Minimum support, as a percentile of the supports of all edges")
+	pconfidencepercentile := flag.Float64("confidencepercentile", 0.0,
+		"Minimum confidence, as a percentile of the confidences of all edges")
+	palpha := flag.Float64("alpha", 0.0,
+		"Significance level of the disparity filter, which extracts the backbone of the graph (0 disables it)")
+	ptopk := flag.Int("topk", 0, "Keep only the k strongest edges of each entity (0 keeps all)")
+	prank := flag.String("rank", "support",
+		"Metric ranking the edges of -topk. One of: support|confidence|lift|jaccard")
+	pcommits := flag.Int("commits", 0,
+		"Number of commits mined, used by lift (default: the sixth column of the MDG)")
+	plevel := flag.String("level", "",
+		"Coarsen entities to modules before filtering. One of: class|file|package|regex")
+	pmodule := flag.String("module", "",
+		"Regular expression of -level=regex, whose first group (or whole match) is the module of an entity")
 	pjoin := flag.Bool("join", false, "Join body and parameters files")
 	pignoreparameters := flag.Bool("ignoreparameters", false, "Ignore parameters files")
 	pcountfile := flag.String("countfile", "", "Path to count file")
+	prepo := flag.String("repo", "", "Path to the Historage repository whose commits are counted, instead of a count file")
+	pmax := flag.Int("max", history.DefaultOptions.Max, "Max commit length, as given to co-change, used by -repo")
+	prange := flag.String("range", "", "Commits range, as given to co-change, used by -repo")
+	pmaxage := flag.String("max-age", "", "[Y][M][D], as given to co-change, used by -repo")
+	plimit := flag.Int("limit", history.DefaultOptions.Limit, "Limit of number of commits, as given to co-change, used by -repo")
+	pignore := flag.String("ignore", "", "Regex of entities to ignore, as given to co-change, used by -repo")
+	pfilter := flag.String("filter", "", "Regex used to filter entities, as given to co-change, used by -repo")
+	pgranularity := flag.String("granularity", history.DefaultOptions.Granularity,
+		"Granularity of consequents, as given to co-change, used by -repo. One of: fine|coarse")
 	pstats := flag.Bool("stats", false, "Print stats and exit")
+	pformat := flag.String("format", "text", "Format of stats. One of: text|json")
+	ptop := flag.Int("top", 10, "Number of entities with the most partners in stats")
+	psweepsupport := flag.String("sweepsupport", "",
+		"Comma separated minimum supports of the stats sweep table (default: -minsupport)")
+	psweepconfidence := flag.String("sweepconfidence", "",
+		"Comma separated minimum confidences of the stats sweep table (default: -minconfidence)")
 	flag.Parse()
-	if pcountfile == nil || *pcountfile == "" {
-		log.Fatal("Count file must be informed")
+	if *psupportpercentile < 0 || *psupportpercentile > 100 ||
+		*pconfidencepercentile < 0 || *pconfidencepercentile > 100 {
+		log.Fatal("Percentiles must be between 0 and 100")
+	}
+	if *palpha < 0 || *palpha >= 1 {
+		log.Fatal("Alpha must be between 0 and 1")
 	}
 
-	joinre1 := regexp.MustCompile("/body$")
-	joinre2 := regexp.MustCompile("/parameters$")
-	joinre3 := regexp.MustCompile("/package$")
-	counts := map[string]int{}
-	cf, err := os.Open(*pcountfile)
+	n := normalization{join: *pjoin, ignoreParameters: *pignoreparameters}
+	m, err := readMDG(os.Stdin, n)
+	if err != nil {
+		log.Fatal(err)
+	}
+	o := history.Options{
+		Range:       *prange,
+		MaxAge:      *pmaxage,
+		Limit:       *plimit,
+		Max:         *pmax,
+		Ignore:      *pignore,
+		Filter:      *pfilter,
+		Granularity: *pgranularity,
+	}
+	counts, commits, err := countEntities(m, n, *pcountfile, *prepo, o)
 	if err != nil {
 		log.Fatal(err)
 	}
-	cs := bufio.NewScanner(cf)
-	for cs.Scan() {
-		arr := strings.Split(cs.Text(), "\t")
-		c, err := strconv.Atoi(arr[1])
+	if *pcommits > 0 {
+		commits = *pcommits
+	}
+	edges := m.edges
+	if *pmodule != "" && *plevel == "" {
+		*plevel = "regex"
+	}
+	if *plevel != "" {
+		module, err := newModule(*plevel, *pmodule)
 		if err != nil {
 			log.Fatal(err)
 		}
-		if *pignoreparameters && joinre2.MatchString(arr[0]) {
-			continue
-		}
-		if *pjoin {
-			if joinre3.MatchString(arr[0]) {
-				continue
-			}
-			arr[0] = joinre1.ReplaceAllLiteralString(arr[0], "")
-			arr[0] = joinre2.ReplaceAllLiteralString(arr[0], "")
-		}
-		counts[arr[0]] += c
+		edges, counts = m.coarsen(module, counts)
+	}
+	g := &graph{edges: edges, counts: counts, commits: commits}
+	f := filters{
+		minSupport:           *pminsupport,
+		minConfidence:        *pminconfidence,
+		minLift:              *pminlift,
+		minJaccard:           *pminjaccard,
+		supportPercentile:    *psupportpercentile,
+		confidencePercentile: *pconfidencepercentile,
+		alpha:                *palpha,
+		topK:                 *ptopk,
+		rank:                 *prank,
 	}
-	if cs.Err() != nil {
-		log.Fatal(cs.Err())
+	pruned, err := g.prune(f)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if g.backbone != nil {
+		fmt.Fprintln(os.Stderr, g.backbone)
 	}
-	cf.Close()
 
-	vertices := map[string]string{}
-	edgescount := 0
-	filterAndPrint := func(arr []string) {
-		support, err := strconv.Atoi(arr[2])
-		if err != nil {
-			log.Fatal(err)
-		}
-		confidence := float64(support) / float64(counts[arr[0]])
-		if support >= *pminsupport && confidence >= *pminconfidence {
-			if *pstats {
-				vertices[arr[0]] = arr[0]
-				vertices[arr[1]] = arr[1]
-				edgescount++
-			} else {
-				fmt.Printf("%v\t%v\t%v\n", arr[0], arr[1], arr[2])
+	if *pstats || *psweepsupport != "" || *psweepconfidence != "" {
+		r := g.newReport(pruned, *ptop)
+		if *psweepsupport != "" || *psweepconfidence != "" {
+			supports := []int{*pminsupport}
+			confidences := []float64{*pminconfidence}
+			if *psweepsupport != "" {
+				if supports, err = parseInts(*psweepsupport); err != nil {
+					log.Fatal(err)
+				}
 			}
-		}
-	}
-	if *pjoin {
-		type edge struct{ source, destination string }
-		graph := map[edge]int{}
-		scanner := bufio.NewScanner(os.Stdin)
-		for scanner.Scan() {
-			arr := strings.Split(scanner.Text(), "\t")
-			if joinre3.MatchString(arr[0]) || joinre3.MatchString(arr[1]) {
-				continue
+			if *psweepconfidence != "" {
+				if confidences, err = parseFloats(*psweepconfidence); err != nil {
+					log.Fatal(err)
+				}
 			}
-			for i := 0; i < 2; i++ {
-				arr[i] = joinre1.ReplaceAllLiteralString(arr[i], "")
-				arr[i] = joinre2.ReplaceAllLiteralString(arr[i], "")
+			if r.Sweep, err = g.sweep(f, supports, confidences); err != nil {
+				log.Fatal(err)
 			}
-			c, err := strconv.Atoi(arr[2])
+		}
+		switch *pformat {
+		case "text":
+			r.writeText(os.Stdout)
+		case "json":
+			b, err := json.MarshalIndent(r, "", "  ")
 			if err != nil {
 				log.Fatal(err)
 			}
-			graph[edge{arr[0], arr[1]}] += c
+			fmt.Println(string(b))
+		default:
+			log.Fatalf("Unknown format %v", *pformat)
 		}
-		if scanner.Err() != nil {
-			log.Fatal(scanner.Err())
+		return
+	}
+	for _, e := range pruned {
+		fmt.Printf("%v\t%v\t%v\n", e.source, e.destination, e.support)
+	}
+}
+
+// countEntities returns the number of commits of each entity and of all
+// entities, taken from the count file or the repository, mined with the
+// options of co-change, if informed, or else from the MDG, which must have
+// the commits of each rule or the antecedent counts
+func countEntities(m *mdg, n normalization, countfile, repo string, o history.Options) (map[string]int, int, error) {
+	switch {
+	case countfile != "":
+		f, err := os.Open(countfile)
+		if err != nil {
+			return nil, 0, err
 		}
-		for k, v := range graph {
-			filterAndPrint([]string{k.source, k.destination, strconv.Itoa(v)})
+		defer f.Close()
+		counts, header, err := readCounts(f)
+		if err != nil {
+			return nil, 0, err
 		}
-	} else {
-		scanner := bufio.NewScanner(os.Stdin)
-		for scanner.Scan() {
-			arr := strings.Split(scanner.Text(), "\t")
-			if *pignoreparameters && (joinre2.MatchString(arr[0]) || joinre2.MatchString(arr[1])) {
-				continue
-			}
-			filterAndPrint(arr)
+		if (header == "") != (m.header == "") {
+			log.Println("Warning: counts and MDG cannot be matched without headers (co-change -header)")
+		}
+		if err := m.validate(counts, header, 0); err != nil {
+			return nil, 0, err
 		}
-		if scanner.Err() != nil {
-			log.Fatal(scanner.Err())
+		return normalizeCounts(counts, n), m.commits, nil
+	case repo != "":
+		// the entities of coarse MDGs include files
+		o.CountFiles = o.Granularity == "coarse"
+		h, err := history.New(history.Command(repo), o)
+		if err != nil {
+			return nil, 0, err
+		}
+		header, err := h.Fingerprint()
+		if err != nil {
+			return nil, 0, err
+		}
+		if m.header == "" {
+			log.Println("Warning: repository and MDG cannot be matched without header (co-change -header)")
+		}
+		counts, commits, err := h.Count()
+		if err != nil {
+			return nil, 0, err
 		}
+		if err := m.validate(counts, header, commits); err != nil {
+			return nil, 0, err
+		}
+		return normalizeCounts(counts, n), commits, nil
+	case len(m.commitsByEntity) > 0:
+		counts, commits := m.countsFromCommits()
+		if m.commits > 0 {
+			commits = m.commits
+		}
+		return counts, commits, nil
+	case len(m.antecedentCounts) > 0:
+		return normalizeCounts(m.antecedentCounts, n), m.commits, nil
 	}
-	if *pstats {
-		fmt.Printf("vertices: %v, edges: %v\n", len(vertices), edgescount)
+	return nil, 0, fmt.Errorf("count file or repository must be informed, " +
+		"unless the MDG has the counts written by co-change")
+}
+
+func parseInts(s string) ([]int, error) {
+	var result []int
+	for _, each := range strings.Split(s, ",") {
+		i, err := strconv.Atoi(strings.TrimSpace(each))
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, i)
+	}
+	return result, nil
+}
+
+func parseFloats(s string) ([]float64, error) {
+	var result []float64
+	for _, each := range strings.Split(s, ",") {
+		f, err := strconv.ParseFloat(strings.TrimSpace(each), 64)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, f)
 	}
+	return result, nil
 }
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/project-draco/tools/mining/co-change/history"
+)
+
+func TestCountEntitiesOfRepository(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pruning")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	git := history.Command(dir)
+	run := func(args ...string) string {
+		out, err := git(args...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	run("init", "--quiet")
+	for i, files := range [][]string{{"A"}, {"A", "B"}, {"A", "B", "C"}} {
+		for _, f := range files {
+			if err := ioutil.WriteFile(filepath.Join(dir, f), []byte{byte('0' + i)}, 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		run("add", "-A")
+		run("-c", "user.name=A", "-c", "user.email=a@b", "commit", "--quiet", "-m", "commit")
+	}
+	o := history.DefaultOptions
+	o.Ignore = "C"
+	h, err := history.New(git, o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header, err := h.Fingerprint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mdg := header + "\nA\tB\t2\t1.0\t2\t2\nB\tA\t2\t1.0\t2\t2\n"
+	m, err := readMDG(strings.NewReader(mdg), normalization{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts, commits, err := countEntities(m, normalization{}, "", dir, o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]int{"A": 2, "B": 2}; !reflect.DeepEqual(counts, want) || commits != 2 {
+		t.Errorf("Got %v %v", counts, commits)
+	}
+	// the options of co-change must be the ones of the MDG
+	if _, _, err := countEntities(m, normalization{}, "", dir, history.DefaultOptions); err == nil {
+		t.Error("Expected error counting with other options")
+	}
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+
+	"github.com/project-draco/naming"
+)
+
+type bucket struct {
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	Count int     `json:"count"`
+}
+
+type partners struct {
+	Entity   string `json:"entity"`
+	Partners int    `json:"partners"`
+}
+
+type sweepRow struct {
+	MinSupport            int     `json:"minSupport"`
+	MinConfidence         float64 `json:"minConfidence"`
+	Vertices              int     `json:"vertices"`
+	Edges                 int     `json:"edges"`
+	Components            int     `json:"components"`
+	LargestComponentShare float64 `json:"largestComponentShare"`
+}
+
+// report describes a pruned graph. Partners, degrees and components
+// disregard the direction of the edges
+type report struct {
+	Vertices              int        `json:"vertices"`
+	Edges                 int        `json:"edges"`
+	Components            int        `json:"components"`
+	LargestComponent      int        `json:"largestComponent"`
+	LargestComponentShare float64    `json:"largestComponentShare"`
+	SameFileEdges         int        `json:"sameFileEdges"`
+	CrossFileEdges        int        `json:"crossFileEdges"`
+	UnknownFileEdges      int        `json:"unknownFileEdges"`
+	Support               []bucket   `json:"support"`
+	Confidence            []bucket   `json:"confidence"`
+	Degree                []bucket   `json:"degree"`
+	TopEntities           []partners `json:"topEntities"`
+	Sweep                 []sweepRow `json:"sweep,omitempty"`
+}
+
+// newReport describes the edges of the graph, listing the top entities
+// with the most partners
+func (g *graph) newReport(edges []edge, top int) *report {
+	r := &report{Edges: len(edges)}
+	adjacency := adjacency(edges)
+	r.Vertices = len(adjacency)
+	r.Components, r.LargestComponent = components(adjacency)
+	if r.Vertices > 0 {
+		r.LargestComponentShare = float64(r.LargestComponent) / float64(r.Vertices)
+	}
+
+	var supports, confidences, degrees []float64
+	for _, e := range edges {
+		supports = append(supports, float64(e.support))
+		confidences = append(confidences, g.confidence(e))
+		f1, f2 := naming.FileFromHR(e.source), naming.FileFromHR(e.destination)
+		switch {
+		case f1 == "" || f2 == "":
+			r.UnknownFileEdges++
+		case f1 == f2:
+			r.SameFileEdges++
+		default:
+			r.CrossFileEdges++
+		}
+	}
+	var entities []partners
+	for v, adj := range adjacency {
+		degrees = append(degrees, float64(len(adj)))
+		entities = append(entities, partners{v, len(adj)})
+	}
+	r.Support = powerOfTwoHistogram(supports)
+	r.Confidence = linearHistogram(confidences, 0, 1, 10)
+	r.Degree = powerOfTwoHistogram(degrees)
+	sort.Slice(entities, func(i, j int) bool {
+		if entities[i].Partners != entities[j].Partners {
+			return entities[i].Partners > entities[j].Partners
+		}
+		return entities[i].Entity < entities[j].Entity
+	})
+	if len(entities) > top {
+		entities = entities[:top]
+	}
+	r.TopEntities = entities
+	return r
+}
+
+// sweep prunes the graph with each combination of minimum support and
+// confidence, the other filters being kept
+func (g *graph) sweep(f filters, supports []int, confidences []float64) ([]sweepRow, error) {
+	var rows []sweepRow
+	for _, s := range supports {
+		for _, c := range confidences {
+			f.minSupport, f.minConfidence = s, c
+			edges, err := g.prune(f)
+			if err != nil {
+				return nil, err
+			}
+			adjacency := adjacency(edges)
+			row := sweepRow{MinSupport: s, MinConfidence: c, Vertices: len(adjacency), Edges: len(edges)}
+			var largest int
+			row.Components, largest = components(adjacency)
+			if row.Vertices > 0 {
+				row.LargestComponentShare = float64(largest) / float64(row.Vertices)
+			}
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+func adjacency(edges []edge) map[string]map[string]bool {
+	result := map[string]map[string]bool{}
+	add := func(v1, v2 string) {
+		if result[v1] == nil {
+			result[v1] = map[string]bool{}
+		}
+		if v1 != v2 {
+			result[v1][v2] = true
+		}
+	}
+	for _, e := range edges {
+		add(e.source, e.destination)
+		add(e.destination, e.source)
+	}
+	return result
+}
+
+// components returns the number of connected components and the size of
+// the largest one
+func components(adjacency map[string]map[string]bool) (int, int) {
+	visited := map[string]bool{}
+	count, largest := 0, 0
+	for v := range adjacency {
+		if visited[v] {
+			continue
+		}
+		count++
+		size := 0
+		stack := []string{v}
+		visited[v] = true
+		for len(stack) > 0 {
+			u := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			size++
+			for w := range adjacency[u] {
+				if !visited[w] {
+					visited[w] = true
+					stack = append(stack, w)
+				}
+			}
+		}
+		if size > largest {
+			largest = size
+		}
+	}
+	return count, largest
+}
+
+// powerOfTwoHistogram counts positive integer values in the buckets 1, 2,
+// 3-4, 5-8, 9-16 and so on, up to the largest value
+func powerOfTwoHistogram(values []float64) []bucket {
+	var result []bucket
+	for _, v := range values {
+		i := 0
+		if v > 1 {
+			i = int(math.Ceil(math.Log2(v)))
+		}
+		for len(result) <= i {
+			min := 1.0
+			max := math.Pow(2, float64(len(result)))
+			if len(result) > 0 {
+				min = math.Pow(2, float64(len(result)-1)) + 1
+			}
+			result = append(result, bucket{Min: min, Max: max})
+		}
+		result[i].Count++
+	}
+	return result
+}
+
+// linearHistogram counts values in n buckets of the same width between min
+// and max. Values out of range are counted in the first or last bucket
+func linearHistogram(values []float64, min, max float64, n int) []bucket {
+	result := make([]bucket, n)
+	width := (max - min) / float64(n)
+	round := func(x float64) float64 { return math.Round(x*1e9) / 1e9 }
+	for i := range result {
+		result[i].Min = round(min + float64(i)*width)
+		result[i].Max = round(min + float64(i+1)*width)
+	}
+	for _, v := range values {
+		i := int((v - min) / width)
+		if i < 0 || math.IsNaN(v) {
+			i = 0
+		}
+		if i >= n {
+			i = n - 1
+		}
+		result[i].Count++
+	}
+	return result
+}
+
+func (r *report) writeText(w io.Writer) {
+	fmt.Fprintf(w, "vertices: %v, edges: %v\n", r.Vertices, r.Edges)
+	fmt.Fprintf(w, "components: %v, largest: %v (%.1f%% of vertices)\n",
+		r.Components, r.LargestComponent, 100*r.LargestComponentShare)
+	fmt.Fprintf(w, "same file edges: %v, cross file edges: %v, unknown file edges: %v\n",
+		r.SameFileEdges, r.CrossFileEdges, r.UnknownFileEdges)
+	integers := func(title string, buckets []bucket) {
+		fmt.Fprintf(w, "\n%v\n", title)
+		for _, b := range buckets {
+			if b.Min == b.Max {
+				fmt.Fprintf(w, "%v\t%v\n", b.Min, b.Count)
+			} else {
+				fmt.Fprintf(w, "%v-%v\t%v\n", b.Min, b.Max, b.Count)
+			}
+		}
+	}
+	integers("support\tedges", r.Support)
+	fmt.Fprintf(w, "\nconfidence\tedges\n")
+	for _, b := range r.Confidence {
+		fmt.Fprintf(w, "%.1f-%.1f\t%v\n", b.Min, b.Max, b.Count)
+	}
+	integers("partners\tvertices", r.Degree)
+	fmt.Fprintf(w, "\npartners\tentity\n")
+	for _, e := range r.TopEntities {
+		fmt.Fprintf(w, "%v\t%v\n", e.Partners, e.Entity)
+	}
+	if len(r.Sweep) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\nminsupport\tminconfidence\tvertices\tedges\tcomponents\tlargest\n")
+	for _, row := range r.Sweep {
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%.1f%%\n", row.MinSupport, row.MinConfidence,
+			row.Vertices, row.Edges, row.Components, 100*row.LargestComponentShare)
+	}
+}
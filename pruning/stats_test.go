@@ -0,0 +1,46 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewReport(t *testing.T) {
+	g := &graph{counts: map[string]int{"f_A.java/[CN]/A/[FE]/a": 4, "f_A.java/[CN]/A/[FE]/b": 2, "x": 1, "y": 1}}
+	edges := []edge{
+		{"f_A.java/[CN]/A/[FE]/a", "f_A.java/[CN]/A/[FE]/b", 3},
+		{"f_A.java/[CN]/A/[FE]/b", "f_A.java/[CN]/A/[FE]/a", 1},
+		{"x", "y", 1},
+	}
+	r := g.newReport(edges, 1)
+	if r.Vertices != 4 || r.Edges != 3 || r.Components != 2 || r.LargestComponent != 2 ||
+		r.LargestComponentShare != 0.5 || r.SameFileEdges != 2 || r.UnknownFileEdges != 1 {
+		t.Errorf("Unexpected report %+v", r)
+	}
+	wantSupport := []bucket{{1, 1, 2}, {2, 2, 0}, {3, 4, 1}}
+	if !reflect.DeepEqual(r.Support, wantSupport) {
+		t.Errorf("Got %v want %v", r.Support, wantSupport)
+	}
+	if r.Confidence[5].Count != 1 || r.Confidence[7].Count != 1 || r.Confidence[9].Count != 1 {
+		t.Errorf("Unexpected confidence histogram %v", r.Confidence)
+	}
+	wantTop := []partners{{"f_A.java/[CN]/A/[FE]/a", 1}}
+	if !reflect.DeepEqual(r.TopEntities, wantTop) {
+		t.Errorf("Got %v want %v", r.TopEntities, wantTop)
+	}
+
+	g.edges = edges
+	rows, err := g.sweep(filters{}, []int{1, 2}, []float64{0, 0.6})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []sweepRow{
+		{1, 0, 4, 3, 2, 0.5},
+		{1, 0.6, 4, 2, 2, 0.5},
+		{2, 0, 2, 1, 1, 1},
+		{2, 0.6, 2, 1, 1, 1},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("Got %v want %v", rows, want)
+	}
+}
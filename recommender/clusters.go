@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/awalterschulze/gographviz"
+)
+
+// clustersAtLevel returns a graph whose subgraphs are the clusters of the
+// given level of a DOT file with nested clusters, such as the hierarchies
+// written by the clustering tool, each one with all the nodes nested in
+// it. Subgraphs without subgraphs are at level 1, and the others one
+// level above their highest subgraph, so all the subgraphs of a DOT file
+// without nested clusters are at level 1
+func clustersAtLevel(g *gographviz.Graph, level int) (*gographviz.Graph, error) {
+	heights := map[string]int{}
+	var height func(name string) int
+	height = func(name string) int {
+		if h, ok := heights[name]; ok {
+			return h
+		}
+		h := 1
+		for _, child := range g.Relations.SortedChildren(name) {
+			if g.IsSubGraph(child) {
+				if hc := height(child) + 1; hc > h {
+					h = hc
+				}
+			}
+		}
+		heights[name] = h
+		return h
+	}
+	var names []string
+	highest := 0
+	for name := range g.SubGraphs.SubGraphs {
+		h := height(name)
+		if h == level {
+			names = append(names, name)
+		}
+		if h > highest {
+			highest = h
+		}
+	}
+	if len(names) == 0 && highest > 0 {
+		return nil, fmt.Errorf("no clusters at level %v, the highest is %v", level, highest)
+	}
+	sort.Strings(names)
+	result := gographviz.NewGraph()
+	result.SetName(g.Name)
+	result.SetDir(g.Directed)
+	for _, name := range names {
+		result.AddSubGraph(g.Name, name, nil)
+		for _, node := range nodesOf(g, name) {
+			result.AddNode(name, node, nil)
+		}
+	}
+	return result, nil
+}
+
+// nodesOf returns the nodes nested in a subgraph, at any depth
+func nodesOf(g *gographviz.Graph, subgraph string) []string {
+	var nodes []string
+	for _, child := range g.Relations.SortedChildren(subgraph) {
+		if g.IsSubGraph(child) {
+			nodes = append(nodes, nodesOf(g, child)...)
+		} else {
+			nodes = append(nodes, child)
+		}
+	}
+	return nodes
+}
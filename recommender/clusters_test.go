@@ -0,0 +1,58 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/awalterschulze/gographviz"
+)
+
+func TestClustersAtLevel(t *testing.T) {
+	cg := gographviz.NewGraph()
+	ast, err := gographviz.ParseString(`
+		digraph {
+			subgraph cluster2_0 {
+				subgraph cluster1_0 {
+					"a";
+					"b";
+				}
+				subgraph cluster1_1 {
+					"c";
+				}
+			}
+			subgraph cluster2_1 {
+				subgraph cluster1_2 {
+					"d";
+				}
+			}
+		}`)
+	checkT(t, err)
+	checkT(t, gographviz.Analyse(ast, cg))
+	for _, test := range []struct {
+		level    int
+		clusters map[string][]string
+	}{
+		{1, map[string][]string{
+			"cluster1_0": {`"a"`, `"b"`},
+			"cluster1_1": {`"c"`},
+			"cluster1_2": {`"d"`},
+		}},
+		{2, map[string][]string{
+			"cluster2_0": {`"a"`, `"b"`, `"c"`},
+			"cluster2_1": {`"d"`},
+		}},
+	} {
+		g, err := clustersAtLevel(cg, test.level)
+		checkT(t, err)
+		clusters := map[string][]string{}
+		for name := range g.SubGraphs.SubGraphs {
+			clusters[name] = g.Relations.SortedChildren(name)
+		}
+		if !reflect.DeepEqual(clusters, test.clusters) {
+			t.Errorf("level %v: expected %v but was %v", test.level, test.clusters, clusters)
+		}
+	}
+	if _, err := clustersAtLevel(cg, 3); err == nil {
+		t.Errorf("Expected an error for level 3")
+	}
+}
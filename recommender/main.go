@@ -2,6 +2,7 @@ package main
 
 import (
 	"bufio"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io/ioutil"
@@ -36,6 +37,11 @@ var before = map[string]float64{
 func main() {
 	output := flag.String("output", "",
 		"one of: smells (default), suggestions, metric, count, csv, metapost")
+	format := flag.String("format", "text",
+		"text|json|jsonl, of smells, suggestions, metric and count; see schema.json, "+
+			"or sarif, of smells and suggestions")
+	sourceDir := flag.String("source-dir", "",
+		"root of the Java source files, to locate the lines of smells in sarif format")
 	dotfile := flag.String("dot-file", "", "")
 	dotdir := flag.String("dot-dir", "", "")
 	minimumSupportCount := flag.Int(
@@ -52,11 +58,19 @@ func main() {
 	supplementalRefactorings := flag.String("supplemental-refactorings", "", "")
 	smells := flag.String("smells", "", "use these smells instead of compute them")
 	configfile := flag.String("config", "", "")
+	level := flag.Int("level", 1, "level of nested clusters treated as clusters, from 1, the innermost")
 	flag.Parse()
 	if flag.NArg() < 3 && *configfile == "" {
 		fmt.Printf("usage: recommender <static mdg file> <co-change mdg file> <errors file> [<inheritance> <field types>]\n")
 		return
 	}
+	if *format != "text" && *format != "json" && *format != "jsonl" && *format != "sarif" {
+		log.Fatalf("invalid format: %v", *format)
+	}
+	if *format != "text" && (*output == "csv" || *output == "metapost") ||
+		*format == "sarif" && (*output == "metric" || *output == "count") {
+		log.Fatalf("output %v has no %v format", *output, *format)
+	}
 	var configs []config
 	if *configfile == "" {
 		var dotfiles []string
@@ -111,12 +125,14 @@ func main() {
 		check(s.Err(), "could not read config")
 	}
 	var improvements [][]map[string]float64
+	document := jsonDocument{SchemaVersion: schemaVersion, Results: []jsonResult{}}
+	sarif := sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []sarifRun{}}
 	if *output == "csv" {
 		fmt.Println("subject;sc;ec;sdc;ccdc;cd;cboo;mpco;pco;ro;fo;uo;cbow;mpcw;pcw;rw;fw;uw")
 	}
 	for i, cfg := range configs {
 		computeMetrics := *output == "metric" || *output == "metapost" || *output == "csv"
-		allsmells, configImprovements, attributes := doAnalysis(
+		allsmells, configImprovements, labels, attributes := doAnalysis(
 			cfg,
 			*output == "suggestions",
 			computeMetrics,
@@ -124,8 +140,38 @@ func main() {
 			*minimumSupportCount,
 			*minimumConfidence,
 			*allowToDependOnCurrentClass,
+			*level,
 		)
-		if *output == "metapost" {
+		subject := *dotdir
+		if subject == "" {
+			subject = cfg.dotfiles[0]
+		}
+		if *format == "sarif" {
+			f, err := os.Open(cfg.cochangemdg)
+			check(err, "could not open co-change mdg file")
+			// the smells of every --smells file, once each
+			var smells []smell
+			seen := map[[2]string]bool{}
+			for _, ss := range allsmells {
+				for _, s := range ss {
+					if !seen[[2]string{s.entity, s.target}] {
+						seen[[2]string{s.entity, s.target}] = true
+						smells = append(smells, s)
+					}
+				}
+			}
+			partners, err := coChangePartners(f, smells)
+			check(err, "could not read co-change mdg file")
+			f.Close()
+			sarif.Runs = append(sarif.Runs, newSARIFRun(subject, smells, partners, *sourceDir))
+		} else if *format != "text" {
+			result := newJSONResult(subject, allsmells, labels, configImprovements)
+			if *format == "json" {
+				document.Results = append(document.Results, result)
+			} else {
+				check(result.writeJSONL(os.Stdout), "could not write results")
+			}
+		} else if *output == "metapost" {
 			improvements = append(improvements, configImprovements)
 		} else if *output == "metric" {
 			for _, imp := range configImprovements {
@@ -157,11 +203,7 @@ func main() {
 			}
 			fmt.Println()
 		} else {
-			if *dotdir == "" {
-				fmt.Print(cfg.dotfiles[0])
-			} else {
-				fmt.Print(*dotdir)
-			}
+			fmt.Print(subject)
 			if *output == "count" {
 				fmt.Printf(": %v\n", len(allsmells[0]))
 			} else {
@@ -178,6 +220,15 @@ func main() {
 	if *output == "metapost" {
 		printMetapost(improvements)
 	}
+	if *format == "json" {
+		data, err := json.MarshalIndent(document, "", "  ")
+		check(err, "could not write results")
+		fmt.Println(string(data))
+	} else if *format == "sarif" {
+		data, err := json.MarshalIndent(sarif, "", "  ")
+		check(err, "could not write results")
+		fmt.Println(string(data))
+	}
 }
 
 func doAnalysis(
@@ -188,7 +239,8 @@ func doAnalysis(
 	minimumSupportCount int,
 	minimumConfidence float64,
 	allowToDependOnCurrentClass bool,
-) ([][]smell, []map[string]float64, map[string]float64) {
+	level int,
+) ([][]smell, []map[string]float64, []string, map[string]float64) {
 	var clusteredgraphs []*gographviz.Graph
 	for _, dotfile := range cfg.dotfiles {
 		if dotfile == "" {
@@ -202,6 +254,8 @@ func doAnalysis(
 		clusteredgraph := gographviz.NewGraph()
 		err = gographviz.Analyse(ast, clusteredgraph)
 		check(err, "could not analyse dot file")
+		clusteredgraph, err = clustersAtLevel(clusteredgraph, level)
+		check(err, "could not select clusters of "+dotfile)
 		clusteredgraphs = append(clusteredgraphs, clusteredgraph)
 	}
 	f1, err := os.Open(cfg.staticmdg)
@@ -246,19 +300,8 @@ func doAnalysis(
 			sf, err := os.Open(smellsFilename)
 			check(err, "could not open smells file")
 			defer sf.Close()
-			var smells []smell
-			s := bufio.NewScanner(sf)
-			for s.Scan() {
-				if strings.TrimSpace(s.Text()) == "" {
-					continue
-				}
-				fields := strings.Split(s.Text(), " -> ")
-				smells = append(smells, smell{
-					entity: fields[0],
-					target: fields[1][:strings.Index(fields[1], " (")],
-				})
-			}
-			check(s.Err(), "could not read smells file")
+			smells, err := readSmells(sf)
+			check(err, "could not read smells file "+smellsFilename)
 			allsmells = append(allsmells, smells)
 		}
 	} else if len(clusteredgraphs) == 0 {
@@ -304,6 +347,15 @@ func doAnalysis(
 		}
 	}
 
+	// labels name the smells and the refactorings of each improvement, in
+	// the order of computeMetrics
+	var labels []string
+	if len(cfg.smells) == 0 {
+		labels = append(labels, "smells")
+	}
+	for _, smellsFilename := range cfg.smells {
+		labels = append(labels, "smells:"+smellsFilename)
+	}
 	var improvements []map[string]float64
 	if metric {
 		fieldTypesFileName := ""
@@ -315,6 +367,12 @@ func doAnalysis(
 		}
 		improvements = computeMetrics(sdfinder, ccdfinder, allsmells, inh,
 			supplementalRefactorings, fieldTypesFileName, f1, f2)
+		for _, sr := range supplementalRefactorings {
+			labels = append(labels, "supplemental:"+sr)
+		}
+		if len(supplementalRefactorings) > 0 {
+			labels = append(labels, "joined")
+		}
 	}
 
 	var clustersdensitysum, avgclustersdensity float64
@@ -331,7 +389,7 @@ func doAnalysis(
 		"clusters-density":             avgclustersdensity,
 	}
 
-	return allsmells, improvements, attrs
+	return allsmells, improvements, labels, attrs
 }
 
 func computeMetrics(
@@ -367,16 +425,36 @@ func computeMetrics(
 		check(err, "could not open supplemental refactorigs file")
 		defer srf.Close()
 		supplementalReassignments := map[string]string{}
-		s := bufio.NewScanner(srf)
-		for s.Scan() {
-			if strings.TrimSpace(s.Text()) == "" {
-				continue
+		// refactorings are lines of a Java name and a target file, separated
+		// by ;, or suggestions in the JSON formats
+		var refactorings [][2]string
+		r := bufio.NewReader(srf)
+		isJSON, err := startsWithJSON(r)
+		check(err, "could not read supplemental refactorings file "+sr)
+		if isJSON {
+			smells, err := readSmells(r)
+			check(err, "could not read supplemental refactorings file "+sr)
+			for _, s := range smells {
+				if s.target != "" {
+					refactorings = append(refactorings, [2]string{s.entity, s.target})
+				}
 			}
-			fields := strings.Split(s.Text(), ";")
-			if len(fields) < 2 {
-				check(fmt.Errorf("invalid refactoring: %v, %v", s.Text(), sr), "")
+		} else {
+			s := bufio.NewScanner(r)
+			for s.Scan() {
+				if strings.TrimSpace(s.Text()) == "" {
+					continue
+				}
+				fields := strings.Split(s.Text(), ";")
+				if len(fields) < 2 {
+					check(fmt.Errorf("invalid refactoring: %v, %v", s.Text(), sr), "")
+				}
+				refactorings = append(refactorings, [2]string{naming.JavaToHR(fields[0]), fields[1]})
 			}
-			ent := entity.Entity(naming.JavaToHR(fields[0]))
+			check(s.Err(), "could not read supplemental refactorings file")
+		}
+		for _, refactoring := range refactorings {
+			ent := entity.Entity(refactoring[0])
 			//TODO: the code bellow checks if the supplemental refactoring will not result in
 			// an improvement because another dependency remains after move. We must check if
 			// this code is necessary
@@ -384,7 +462,7 @@ func computeMetrics(
 				nil,
 				ent.QueryString(),
 				ent.Filename(),
-				[]string{fields[1]},
+				[]string{refactoring[1]},
 				sdfinder,
 				ccdfinder,
 				nil,
@@ -394,7 +472,6 @@ func computeMetrics(
 				joinedReassignments[ent.QueryString()] = bestCandidate
 			}
 		}
-		check(s.Err(), "could not read supplemental refactorings file")
 		reassignments = append(reassignments, supplementalReassignments)
 	}
 	if len(supplementalRefactorings) > 0 {
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// schemaVersion is the version of the JSON schema of the results, in
+// schema.json. It changes only when fields are removed or change meaning
+const schemaVersion = 1
+
+// jsonDocument is the output of --format=json: the results of each
+// subject, a DOT file or directory, or the MDGs of a config line
+type jsonDocument struct {
+	SchemaVersion int          `json:"schemaVersion"`
+	Results       []jsonResult `json:"results"`
+}
+
+type jsonResult struct {
+	Subject string      `json:"subject"`
+	Smells  []jsonSmell `json:"smells"`
+	// Metrics are the metrics after each set of refactorings
+	Metrics []jsonMetrics `json:"metrics,omitempty"`
+}
+
+// jsonSmell is an evolutionary smell: an entity, in a file, that co-changes
+// with the candidate files. A suggestion is a smell with the target file to
+// which the entity should be moved, removing depCount dependencies.
+// Refactorings is the label of the metrics of the smells it belongs to,
+// smells or smells:<file> for those read from a file
+type jsonSmell struct {
+	Entity       string          `json:"entity"`
+	File         string          `json:"file,omitempty"`
+	Target       string          `json:"target,omitempty"`
+	DepCount     int             `json:"depCount"`
+	Candidates   []jsonCandidate `json:"candidates"`
+	Refactorings string          `json:"refactorings,omitempty"`
+}
+
+type jsonCandidate struct {
+	File     string `json:"file"`
+	DepCount int    `json:"depCount"`
+}
+
+// jsonMetrics are the metrics after moving the entities of a set of
+// refactorings, relative to the metrics before, and their deltas, the
+// changes of the metrics with a baseline. Metrics that are not finite, as
+// cbo without dependencies, are left out
+type jsonMetrics struct {
+	Refactorings string             `json:"refactorings"`
+	Values       map[string]float64 `json:"values"`
+	Deltas       map[string]float64 `json:"deltas"`
+}
+
+// jsonRecord is a line of the output of --format=jsonl: a smell, a
+// suggestion or metrics, along with the schema version and the subject.
+// Refactorings, of the smell or the metrics, is a field of the record, as
+// the fields of both would conflict and be left out
+type jsonRecord struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	Type          string `json:"type"`
+	Subject       string `json:"subject"`
+	Refactorings  string `json:"refactorings,omitempty"`
+	*jsonSmell
+	*jsonMetrics
+}
+
+func newJSONSmell(s smell, refactorings string) jsonSmell {
+	js := jsonSmell{
+		Entity:       s.entity,
+		File:         s.filename,
+		Target:       s.target,
+		DepCount:     s.depcount,
+		Candidates:   []jsonCandidate{},
+		Refactorings: refactorings,
+	}
+	for _, c := range s.candidates {
+		js.Candidates = append(js.Candidates, jsonCandidate{c.name, c.depcount})
+	}
+	return js
+}
+
+func (js jsonSmell) smell() smell {
+	s := smell{entity: js.Entity, filename: js.File, target: js.Target, depcount: js.DepCount}
+	for _, c := range js.Candidates {
+		s.candidates = append(s.candidates, candidate{c.File, c.DepCount})
+	}
+	return s
+}
+
+// newJSONResult returns the smells of every set, each labelled as the
+// metrics of its refactorings, and the metrics of every set of refactorings
+func newJSONResult(subject string, allsmells [][]smell, labels []string, improvements []map[string]float64) jsonResult {
+	r := jsonResult{Subject: subject, Smells: []jsonSmell{}}
+	for i, smells := range allsmells {
+		for _, s := range smells {
+			r.Smells = append(r.Smells, newJSONSmell(s, labels[i]))
+		}
+	}
+	for i, imp := range improvements {
+		m := jsonMetrics{Refactorings: labels[i], Values: map[string]float64{}, Deltas: map[string]float64{}}
+		for metric, v := range imp {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				continue
+			}
+			m.Values[metric] = v
+			if b, ok := before[metric]; ok {
+				m.Deltas[metric] = v - b
+			}
+		}
+		r.Metrics = append(r.Metrics, m)
+	}
+	return r
+}
+
+// writeJSONL writes a line per smell or suggestion and per set of metrics
+func (r jsonResult) writeJSONL(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	for i := range r.Smells {
+		t := "smell"
+		if r.Smells[i].Target != "" {
+			t = "suggestion"
+		}
+		if err := enc.Encode(jsonRecord{schemaVersion, t, r.Subject, r.Smells[i].Refactorings, &r.Smells[i], nil}); err != nil {
+			return err
+		}
+	}
+	for i := range r.Metrics {
+		if err := enc.Encode(jsonRecord{schemaVersion, "metrics", r.Subject, r.Metrics[i].Refactorings, nil, &r.Metrics[i]}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// startsWithJSON discards the byte order mark and the whitespace before
+// the content of r, and returns whether it is JSON, starting with {
+func startsWithJSON(r *bufio.Reader) (bool, error) {
+	if b, err := r.Peek(3); err == nil && string(b) == "\xef\xbb\xbf" {
+		r.Discard(3)
+	}
+	for {
+		b, err := r.Peek(1)
+		if err == io.EOF {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		if !unicode.IsSpace(rune(b[0])) {
+			return b[0] == '{', nil
+		}
+		r.Discard(1)
+	}
+}
+
+// readSmells reads the smells written with --format=json or jsonl or, if
+// the first character is not {, the lines written with --format=text, of
+// which only the entity and the target are read
+func readSmells(r io.Reader) ([]smell, error) {
+	br := bufio.NewReader(r)
+	isJSON, err := startsWithJSON(br)
+	if err != nil {
+		return nil, err
+	}
+	if isJSON {
+		return readJSONSmells(br)
+	}
+	var smells []smell
+	s := bufio.NewScanner(br)
+	for s.Scan() {
+		if strings.TrimSpace(s.Text()) == "" {
+			continue
+		}
+		fields := strings.Split(s.Text(), " -> ")
+		if len(fields) < 2 || !strings.Contains(fields[1], " (") {
+			return nil, fmt.Errorf("invalid smell: %v", s.Text())
+		}
+		smells = append(smells, smell{
+			entity: fields[0],
+			target: fields[1][:strings.Index(fields[1], " (")],
+		})
+	}
+	return smells, s.Err()
+}
+
+// readJSONSmells reads the smells and suggestions of documents or records
+func readJSONSmells(r io.Reader) ([]smell, error) {
+	var smells []smell
+	dec := json.NewDecoder(r)
+	for {
+		// a document has results, and a record a type and its fields
+		var v struct {
+			SchemaVersion int          `json:"schemaVersion"`
+			Results       []jsonResult `json:"results"`
+			Type          string       `json:"type"`
+			jsonSmell
+		}
+		if err := dec.Decode(&v); err == io.EOF {
+			return smells, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if v.SchemaVersion > schemaVersion {
+			return nil, fmt.Errorf("schema version %v is newer than %v", v.SchemaVersion, schemaVersion)
+		}
+		for _, result := range v.Results {
+			for _, js := range result.Smells {
+				smells = append(smells, js.smell())
+			}
+		}
+		if t := v.Type; t == "smell" || t == "suggestion" {
+			smells = append(smells, v.jsonSmell.smell())
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadSmells(t *testing.T) {
+	smells := []smell{
+		{entity: "p_A.java/[CN]/A/[MT]/m()", filename: "A", target: "B", depcount: 2,
+			candidates: []candidate{{"B", 2}, {"C", 1}}},
+		{entity: "p_A.java/[CN]/A/[FE]/f", filename: "A", candidates: []candidate{{"C", 0}}},
+	}
+	// the smells of every file are written, labelled as their metrics
+	result := newJSONResult("clusters.dot", [][]smell{smells[:1], smells[1:]},
+		[]string{"smells:a.jsonl", "smells:b.jsonl", "joined"},
+		[]map[string]float64{{"reusability": 1.5, "cbo": math.NaN()}, {}, {}})
+	if m := result.Metrics[0]; !reflect.DeepEqual(m.Values, map[string]float64{"reusability": 1.5}) ||
+		!reflect.DeepEqual(m.Deltas, map[string]float64{"reusability": 0.5}) {
+		t.Errorf("unexpected metrics: %+v", m)
+	}
+	for i, want := range []string{"smells:a.jsonl", "smells:b.jsonl"} {
+		if got := result.Smells[i].Refactorings; got != want || result.Metrics[i].Refactorings != want {
+			t.Errorf("expected the smells and metrics of %v, got %v and %v", want, got, result.Metrics[i].Refactorings)
+		}
+	}
+	var jsonl bytes.Buffer
+	checkT(t, result.writeJSONL(&jsonl))
+	for i, line := range strings.Split(strings.TrimSpace(jsonl.String()), "\n") {
+		var r struct{ Refactorings string }
+		checkT(t, json.Unmarshal([]byte(line), &r))
+		if want := []string{"smells:a.jsonl", "smells:b.jsonl", "smells:a.jsonl", "smells:b.jsonl", "joined"}[i]; r.Refactorings != want {
+			t.Errorf("line %v: expected the refactorings %v, got %v", i, want, line)
+		}
+	}
+	document, err := json.Marshal(jsonDocument{schemaVersion, []jsonResult{result}})
+	checkT(t, err)
+	// JSON may follow a byte order mark and whitespace
+	for _, input := range []string{jsonl.String(), string(document), "\xef\xbb\xbf\n " + string(document)} {
+		got, err := readSmells(strings.NewReader(input))
+		checkT(t, err)
+		if !reflect.DeepEqual(got, smells) {
+			t.Errorf("expected %v, got %v", smells, got)
+		}
+	}
+	var text bytes.Buffer
+	for _, s := range smells[:1] {
+		text.WriteString(s.String() + "\n\n")
+	}
+	got, err := readSmells(&text)
+	checkT(t, err)
+	if want := []smell{{entity: smells[0].entity, target: "B"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if _, err := readSmells(strings.NewReader(`{"schemaVersion":2,"results":[]}`)); err == nil {
+		t.Error("expected an error for a newer schema version")
+	}
+}
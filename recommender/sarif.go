@@ -0,0 +1,385 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/project-draco/naming"
+	"github.com/project-draco/pkg/dependency-scanner"
+	"github.com/project-draco/pkg/entity"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	smellRuleID  = "evolutionary-smell"
+	// maxCoChangePartners is the maximum number of co-change partners of
+	// a result
+	maxCoChangePartners = 10
+	// fingerprintKey names the fingerprint of the smells, a hash of the
+	// rule and the normalized entity, so that it does not change when the
+	// entity moves within its file or its suggested target changes
+	fingerprintKey = "evolutionarySmell/v1"
+)
+
+// sarifLog is the output of --format=sarif, with a run per subject
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool               sarifTool                        `json:"tool"`
+	AutomationDetails  *sarifAutomationDetails          `json:"automationDetails,omitempty"`
+	OriginalURIBaseIDs map[string]sarifArtifactLocation `json:"originalUriBaseIds,omitempty"`
+	Results            []sarifResult                    `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	Name                 string             `json:"name"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	FullDescription      sarifMessage       `json:"fullDescription"`
+	HelpURI              string             `json:"helpUri"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifAutomationDetails struct {
+	ID string `json:"id"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Message             sarifMessage      `json:"message"`
+	Locations           []sarifLocation   `json:"locations"`
+	RelatedLocations    []sarifLocation   `json:"relatedLocations,omitempty"`
+	PartialFingerprints map[string]string `json:"partialFingerprints"`
+	Properties          sarifProperties   `json:"properties"`
+}
+
+type sarifProperties struct {
+	Entity   string `json:"entity"`
+	Target   string `json:"target,omitempty"`
+	DepCount int    `json:"depCount"`
+}
+
+type sarifLocation struct {
+	ID               int                    `json:"id,omitempty"`
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+	Message          *sarifMessage          `json:"message,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+type sarifLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// javaMember is the Java source file, classes and member of a Historage
+// entity, such as src_p_C.java/[CN]/C/[CN]/Inner/[MT]/m(int), whose file is
+// src/p/C.java, its classes C and Inner, and member m(int)
+type javaMember struct {
+	file    string
+	classes []string
+	kind    string
+	member  string
+}
+
+func newJavaMember(e string) (javaMember, bool) {
+	idx := strings.Index(e, ".java/[CN]/")
+	if idx == -1 {
+		return javaMember{}, false
+	}
+	path := e[:idx]
+	if slash := strings.LastIndex(path, "/"); slash != -1 {
+		path = path[slash+1:]
+	}
+	m := javaMember{file: strings.Replace(path, "_", "/", -1) + ".java"}
+	for _, part := range strings.Split(e[idx+len(".java"):], "/[CN]/")[1:] {
+		fields := strings.SplitN(part, "/", 3)
+		m.classes = append(m.classes, fields[0])
+		if len(fields) == 3 {
+			m.kind, m.member = fields[1], fields[2]
+		}
+	}
+	m.member = strings.TrimSuffix(strings.TrimSuffix(m.member, "/body"), "/parameters")
+	return m, m.member != ""
+}
+
+func (m javaMember) class() string {
+	return m.classes[len(m.classes)-1]
+}
+
+func (m javaMember) name() string {
+	if idx := strings.Index(m.member, "("); idx != -1 {
+		return m.member[:idx]
+	}
+	return m.member
+}
+
+func (m javaMember) description() string {
+	switch m.kind {
+	case "[MT]":
+		return "method"
+	case "[CS]":
+		return "constructor"
+	}
+	return "field"
+}
+
+func (m javaMember) logicalLocation() sarifLogicalLocation {
+	kind := "function"
+	if m.kind == "[FE]" {
+		kind = "member"
+	}
+	return sarifLogicalLocation{m.member, strings.Join(m.classes, ".") + "." + m.member, kind}
+}
+
+// line returns the first line of the file declaring the member, or 0 if
+// there is none or it cannot be read
+func (m javaMember) line(filename string) int {
+	f, err := os.Open(filename)
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+	declaration := `\b` + regexp.QuoteMeta(m.name()) + `\s*\(`
+	if m.kind == "[FE]" {
+		declaration = `\b` + regexp.QuoteMeta(m.name()) + `\s*[=;,]`
+	}
+	re := regexp.MustCompile(declaration)
+	s := bufio.NewScanner(f)
+	for i := 1; s.Scan(); i++ {
+		line := strings.TrimSpace(s.Text())
+		if re.MatchString(line) && !strings.HasPrefix(line, "return") &&
+			!strings.HasPrefix(line, "//") && !strings.HasPrefix(line, "*") {
+			return i
+		}
+	}
+	return 0
+}
+
+// location returns the file of the member and, with sourceDir, the line
+// declaring it. Since Historage replaces every / of the path by _, the
+// directories and files of sourceDir decide which _ are separators
+func (m javaMember) location(sourceDir string) *sarifPhysicalLocation {
+	l := &sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{m.file, "SRCROOT"}}
+	if sourceDir == "" {
+		return l
+	}
+	if file, ok := findFile(sourceDir, strings.Split(m.file, "/")); ok {
+		l.ArtifactLocation.URI = file
+	}
+	if line := m.line(filepath.Join(sourceDir, filepath.FromSlash(l.ArtifactLocation.URI))); line > 0 {
+		l.Region = &sarifRegion{line}
+	}
+	return l
+}
+
+// findFile returns the path of the file under dir whose name is the parts
+// joined by / or _, trying the shortest directory names first
+func findFile(dir string, parts []string) (string, bool) {
+	for i := 1; i <= len(parts); i++ {
+		name := strings.Join(parts[:i], "_")
+		fi, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		if i == len(parts) {
+			if !fi.IsDir() {
+				return name, true
+			}
+		} else if fi.IsDir() {
+			if rest, ok := findFile(filepath.Join(dir, name), parts[i:]); ok {
+				return name + "/" + rest, true
+			}
+		}
+	}
+	return "", false
+}
+
+// coChangePartner is an entity of another file co-changing with an entity
+type coChangePartner struct {
+	entity       string
+	supportCount int
+}
+
+// coChangePartners returns the entities of other files co-changing with
+// each entity of the smells, by query string, once each with its largest
+// support count, in decreasing order of it and up to maxCoChangePartners.
+// Co-change MDGs have both directions of each rule, so partners are found
+// in either of them
+func coChangePartners(r io.ReadSeeker, smells []smell) (map[string][]coChangePartner, error) {
+	supports := map[string]map[string]int{}
+	for _, s := range smells {
+		supports[entity.Entity(s.entity).QueryString()] = map[string]int{}
+	}
+	r.Seek(0, 0)
+	s := scanner.NewDependencyScanner(r)
+	for s.Scan() {
+		d := s.Dependency()
+		for _, from := range d.From {
+			pair := [2]string{from, d.To}
+			for i := range pair {
+				qs := entity.Entity(pair[i]).QueryString()
+				other := pair[1-i]
+				if _, ok := supports[qs]; !ok ||
+					entity.Entity(other).Filename() == entity.Entity(pair[i]).Filename() {
+					continue
+				}
+				if d.SupportCount > supports[qs][other] {
+					supports[qs][other] = d.SupportCount
+				}
+			}
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	partners := map[string][]coChangePartner{}
+	for qs, m := range supports {
+		var pp []coChangePartner
+		for e, supportCount := range m {
+			pp = append(pp, coChangePartner{e, supportCount})
+		}
+		sort.Slice(pp, func(i, j int) bool {
+			if pp[i].supportCount != pp[j].supportCount {
+				return pp[i].supportCount > pp[j].supportCount
+			}
+			return pp[i].entity < pp[j].entity
+		})
+		if len(pp) > maxCoChangePartners {
+			pp = pp[:maxCoChangePartners]
+		}
+		partners[qs] = pp
+	}
+	return partners, nil
+}
+
+// fingerprint hashes the rule and the entity, without the /body and
+// /parameters suffixes and generics, which identify the member by its
+// file, classes and signature, unlike its query string
+func fingerprint(e string) string {
+	e = naming.RemoveGenerics(strings.TrimSuffix(strings.TrimSuffix(e, "/body"), "/parameters"))
+	hash := sha256.Sum256([]byte(smellRuleID + "\n" + e))
+	return hex.EncodeToString(hash[:])
+}
+
+func newSARIFRun(subject string, smells []smell, partners map[string][]coChangePartner, sourceDir string) sarifRun {
+	run := sarifRun{
+		Tool: sarifTool{sarifDriver{
+			Name:           "draco-recommender",
+			InformationURI: "https://github.com/project-draco/tools/tree/master/recommender",
+			Rules: []sarifRule{{
+				ID:   smellRuleID,
+				Name: "EvolutionarySmell",
+				ShortDescription: sarifMessage{
+					"Method or field co-changes with another class"},
+				FullDescription: sarifMessage{
+					"The method or field co-changes with entities of another class, " +
+						"but has no dependency on the other entities of its own class, " +
+						"so it may have been declared in the wrong class"},
+				HelpURI:              "https://github.com/project-draco/tools/tree/master/recommender#detecting-evolutionary-smells",
+				DefaultConfiguration: sarifConfiguration{"warning"},
+			}},
+		}},
+		Results: []sarifResult{},
+	}
+	if sourceDir != "" {
+		if abs, err := filepath.Abs(sourceDir); err == nil {
+			run.OriginalURIBaseIDs = map[string]sarifArtifactLocation{
+				"SRCROOT": {URI: "file://" + filepath.ToSlash(abs) + "/"},
+			}
+		}
+	}
+	if subject != "" {
+		run.AutomationDetails = &sarifAutomationDetails{"recommender/" + subject + "/"}
+	}
+	for _, s := range smells {
+		m, ok := newJavaMember(s.entity)
+		if !ok {
+			continue
+		}
+		qs := entity.Entity(s.entity).QueryString()
+		result := sarifResult{
+			RuleID:              smellRuleID,
+			Locations:           []sarifLocation{{PhysicalLocation: m.location(sourceDir)}},
+			PartialFingerprints: map[string]string{fingerprintKey: fingerprint(s.entity)},
+			Properties:          sarifProperties{s.entity, s.target, s.depcount},
+		}
+		result.Locations[0].LogicalLocations = []sarifLogicalLocation{m.logicalLocation()}
+		var with []string
+		for i, p := range partners[qs] {
+			pm, ok := newJavaMember(p.entity)
+			if !ok {
+				continue
+			}
+			name := pm.class() + "." + pm.member
+			with = append(with, fmt.Sprintf("%v (support count %v)", name, p.supportCount))
+			result.RelatedLocations = append(result.RelatedLocations, sarifLocation{
+				ID:               i + 1,
+				PhysicalLocation: pm.location(sourceDir),
+				LogicalLocations: []sarifLogicalLocation{pm.logicalLocation()},
+				Message:          &sarifMessage{fmt.Sprintf("co-changes %v times", p.supportCount)},
+			})
+		}
+		text := fmt.Sprintf("The %v %v of class %v", m.description(), m.member, m.class())
+		if len(with) > 0 {
+			text += " co-changes with " + strings.Join(with, ", ")
+		} else {
+			text += " co-changes with other classes"
+		}
+		if s.target != "" {
+			text += fmt.Sprintf("; consider moving it to class %v", s.target)
+			if s.depcount > 0 {
+				text += fmt.Sprintf(", which removes %v dependencies", s.depcount)
+			}
+		}
+		result.Message = sarifMessage{text}
+		run.Results = append(run.Results, result)
+	}
+	return run
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/project-draco/pkg/entity"
+)
+
+func TestNewJavaMember(t *testing.T) {
+	for _, test := range []struct {
+		entity string
+		want   javaMember
+	}{
+		{"src_p_C.java/[CN]/C/[MT]/m(int)",
+			javaMember{"src/p/C.java", []string{"C"}, "[MT]", "m(int)"}},
+		{"src_p_C.java/[CN]/C/[CN]/Inner/[FE]/f",
+			javaMember{"src/p/C.java", []string{"C", "Inner"}, "[FE]", "f"}},
+		{"src_p_C.java/[CN]/C/[MT]/m()/body",
+			javaMember{"src/p/C.java", []string{"C"}, "[MT]", "m()"}},
+	} {
+		got, ok := newJavaMember(test.entity)
+		if !ok || !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%v: expected %+v, got %+v", test.entity, test.want, got)
+		}
+	}
+	if _, ok := newJavaMember("src_p_C.java/[CN]/C/extend"); ok {
+		t.Error("expected no member of a class")
+	}
+}
+
+func TestNewSARIFRun(t *testing.T) {
+	// co-change MDGs have both directions of each rule
+	cochange := strings.NewReader(
+		"p_A.java/[CN]/A/[MT]/m()\tp_B.java/[CN]/B/[MT]/n()\t2\t0.5\n" +
+			"p_B.java/[CN]/B/[MT]/n()\tp_A.java/[CN]/A/[MT]/m()\t2\t0.4\n" +
+			"p_C.java/[CN]/C/[FE]/f\tp_A.java/[CN]/A/[MT]/m()\t3\t0.6\n" +
+			"p_A.java/[CN]/A/[MT]/m()\tp_C.java/[CN]/C/[FE]/f\t3\t0.3\n" +
+			"p_A.java/[CN]/A/[FE]/g\tp_A.java/[CN]/A/[MT]/m()\t5\t1\n" +
+			"p_A.java/[CN]/A/[MT]/m()\tp_A.java/[CN]/A/[FE]/g\t5\t0.5\n")
+	smells := []smell{{entity: "p_A.java/[CN]/A/[MT]/m()", target: "C", depcount: 2}}
+	partners, err := coChangePartners(cochange, smells)
+	checkT(t, err)
+	run := newSARIFRun("", smells, partners, "")
+	want := "The method m() of class A co-changes with C.f (support count 3), B.n() (support count 2); " +
+		"consider moving it to class C, which removes 2 dependencies"
+	if len(run.Results) != 1 || run.Results[0].Message.Text != want {
+		t.Fatalf("expected %q, got %+v", want, run.Results)
+	}
+	if n := len(run.Results[0].RelatedLocations); n != 2 {
+		t.Errorf("expected 2 related locations, got %v", n)
+	}
+	// the fingerprint depends only on the entity, not on its target
+	smells[0].target = "B"
+	other := newSARIFRun("other", smells, nil, "")
+	if !reflect.DeepEqual(run.Results[0].PartialFingerprints, other.Results[0].PartialFingerprints) {
+		t.Errorf("expected equal fingerprints, got %v and %v",
+			run.Results[0].PartialFingerprints, other.Results[0].PartialFingerprints)
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	// entities of classes of the same name in other packages differ
+	if fingerprint("src_a_Util.java/[CN]/Util/[MT]/m()") == fingerprint("src_b_Util.java/[CN]/Util/[MT]/m()") {
+		t.Error("expected different fingerprints of classes of other packages")
+	}
+	if fingerprint("A.java/[CN]/A/[CN]/B/[FE]/f") == fingerprint("A.java/[CN]/B/[FE]/f") {
+		t.Error("expected different fingerprints of inner and outer classes")
+	}
+	if fingerprint("A.java/[CN]/A/[MT]/m(List<String>)/body") != fingerprint("A.java/[CN]/A/[MT]/m(List)") {
+		t.Error("expected equal fingerprints without suffixes and generics")
+	}
+}
+
+func TestLocation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sarif")
+	checkT(t, err)
+	defer os.RemoveAll(dir)
+	checkT(t, os.MkdirAll(filepath.Join(dir, "src", "my_pkg", "a"), 0755))
+	source := "class My_Class {\n  void m() {\n  }\n}\n"
+	checkT(t, ioutil.WriteFile(filepath.Join(dir, "src", "my_pkg", "a", "My_Class.java"), []byte(source), 0644))
+	m, ok := newJavaMember("src_my_pkg_a_My_Class.java/[CN]/My_Class/[MT]/m()")
+	if !ok {
+		t.Fatal("expected a member")
+	}
+	if got := m.location(""); got.ArtifactLocation.URI != "src/my/pkg/a/My/Class.java" || got.Region != nil {
+		t.Errorf("expected every _ as a separator without source dir, got %+v", got)
+	}
+	got := m.location(dir)
+	if got.ArtifactLocation.URI != "src/my_pkg/a/My_Class.java" || got.Region == nil || got.Region.StartLine != 2 {
+		t.Errorf("expected the file of the source dir, got %+v %+v", got.ArtifactLocation, got.Region)
+	}
+}
+
+func TestCoChangePartnersLimit(t *testing.T) {
+	var mdg strings.Builder
+	for i := 0; i < maxCoChangePartners+2; i++ {
+		fmt.Fprintf(&mdg, "p_A.java/[CN]/A/[MT]/m()\tp_B%v.java/[CN]/B%v/[MT]/n()\t%v\t0.5\n", i, i, i+1)
+		fmt.Fprintf(&mdg, "p_B%v.java/[CN]/B%v/[MT]/n()\tp_A.java/[CN]/A/[MT]/m()\t%v\t0.5\n", i, i, i+1)
+	}
+	smells := []smell{{entity: "p_A.java/[CN]/A/[MT]/m()"}}
+	partners, err := coChangePartners(strings.NewReader(mdg.String()), smells)
+	checkT(t, err)
+	pp := partners[entity.Entity(smells[0].entity).QueryString()]
+	if len(pp) != maxCoChangePartners || pp[0].supportCount != maxCoChangePartners+2 ||
+		pp[len(pp)-1].supportCount != 3 {
+		t.Errorf("expected the %v partners of largest support, got %v", maxCoChangePartners, partners)
+	}
+}